@@ -2,80 +2,402 @@ package cmd
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 
 	"watchducker/internal/core"
+	"watchducker/internal/docker"
 	"watchducker/internal/types"
 	"watchducker/pkg/config"
 	"watchducker/pkg/logger"
 	"watchducker/pkg/notify"
+	"watchducker/pkg/state"
 	"watchducker/pkg/utils"
 
 	"github.com/robfig/cron/v3"
 )
 
+// runOutcome 一次运行的结果统计，用于决定 --once 模式的退出码
+type runOutcome struct {
+	updated int                       // 有更新的镜像数
+	failed  int                       // 检查或更新失败的数量
+	results []*types.BatchCheckResult // 各主机的检查结果
+}
+
+// add 合并另一次运行的结果统计
+func (o *runOutcome) add(other runOutcome) {
+	o.updated += other.updated
+	o.failed += other.failed
+	o.results = append(o.results, other.results...)
+}
+
+// exitCode 根据结果统计计算退出码：失败为 1，有更新为 --exit-code-on-update，否则为 0
+func (o runOutcome) exitCode() int {
+	if o.failed > 0 {
+		return 1
+	}
+	if o.updated > 0 {
+		return config.Get().ExitCodeOnUpdate()
+	}
+	return 0
+}
+
 // checkContainersByName 根据容器名称检查镜像更新
-func checkContainersByName(ctx context.Context) {
+func checkContainersByName(ctx context.Context) runOutcome {
 	cfg := config.Get()
-	RunChecker(ctx, func(checker *core.Checker) (*types.BatchCheckResult, error) {
-		return checker.CheckByName(ctx, utils.UniqueDifference(cfg.ContainerNames(), cfg.DisabledContainers()))
+	return RunChecker(ctx, func(checker *core.Checker) (*types.BatchCheckResult, error) {
+		return checker.CheckByName(ctx, utils.UniqueDifference(cfg.ContainerNames(), cfg.DisabledContainers()), cfg.DisabledContainers())
 	})
 }
 
 // checkContainersByLabel 根据标签检查镜像更新
-func checkContainersByLabel(ctx context.Context) {
+func checkContainersByLabel(ctx context.Context) runOutcome {
 	labelKey, labelValue := "watchducker.update", "true"
 	cfg := config.Get()
 
-	RunChecker(ctx, func(checker *core.Checker) (*types.BatchCheckResult, error) {
+	return RunChecker(ctx, func(checker *core.Checker) (*types.BatchCheckResult, error) {
 		return checker.CheckByLabel(ctx, labelKey, labelValue, cfg.DisabledContainers())
 	})
 }
 
+// checkContainersByNameAndLabel 检查指定名称的容器以及带有标签的容器
+func checkContainersByNameAndLabel(ctx context.Context) runOutcome {
+	labelKey, labelValue := "watchducker.update", "true"
+	cfg := config.Get()
+
+	return RunChecker(ctx, func(checker *core.Checker) (*types.BatchCheckResult, error) {
+		return checker.CheckByNameAndLabel(ctx, cfg.ContainerNames(), labelKey, labelValue, cfg.DisabledContainers())
+	})
+}
+
+// checkContainersByProject 检查指定 compose 项目的容器
+func checkContainersByProject(ctx context.Context) runOutcome {
+	cfg := config.Get()
+
+	return RunChecker(ctx, func(checker *core.Checker) (*types.BatchCheckResult, error) {
+		return checker.CheckByProject(ctx, cfg.Projects(), cfg.DisabledContainers())
+	})
+}
+
 // checkAllContainers 检查所有容器的镜像更新
-func checkAllContainers(ctx context.Context) {
+func checkAllContainers(ctx context.Context) runOutcome {
 	cfg := config.Get()
 
-	RunChecker(ctx, func(checker *core.Checker) (*types.BatchCheckResult, error) {
+	return RunChecker(ctx, func(checker *core.Checker) (*types.BatchCheckResult, error) {
 		return checker.CheckAll(ctx, cfg.DisabledContainers())
 	})
 }
 
+// notifyTitle 在通知标题后附加实例名称，便于区分多主机、多实例的消息来源
+func notifyTitle(title string) string {
+	if name := config.Get().InstanceName(); name != "" {
+		return fmt.Sprintf("%s [%s]", title, name)
+	}
+	return title
+}
+
+// checkImages 直接检查指定镜像，不查找容器也不执行更新
+func checkImages(ctx context.Context) runOutcome {
+	cfg := config.Get()
+
+	return RunChecker(ctx, func(checker *core.Checker) (*types.BatchCheckResult, error) {
+		return checker.CheckImages(ctx, cfg.Images())
+	})
+}
+
 // checkContainersByLabelReversed 检查没有传入标签的容器
-func checkContainersByLabelReversed(ctx context.Context) {
+func checkContainersByLabelReversed(ctx context.Context) runOutcome {
 	labelKey, labelValue := "watchducker.update", "true"
 	cfg := config.Get()
 
-	RunChecker(ctx, func(checker *core.Checker) (*types.BatchCheckResult, error) {
+	return RunChecker(ctx, func(checker *core.Checker) (*types.BatchCheckResult, error) {
 		return checker.CheckByLabelReversed(ctx, labelKey, labelValue, cfg.DisabledContainers())
 	})
 }
 
-// RunOnce 单次执行模式
-func RunOnce(ctx context.Context) {
+// RunOnce 单次执行模式，返回反映运行结果的退出码
+func RunOnce(ctx context.Context) int {
 	cfg := config.Get()
+	var outcome runOutcome
+
+	// 限制单次运行的时长，超时后取消所有进行中的检查和更新，避免与下次触发重叠
+	if cfg.RunTimeout() > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cfg.RunTimeout())
+		defer cancel()
+	}
 
-	if len(cfg.ContainerNames()) > 0 {
-		checkContainersByName(ctx)
+	if cfg.PreRunHook() != "" {
+		if err := runHook(ctx, cfg.PreRunHook(), nil); err != nil {
+			logger.Error("运行前钩子执行失败，跳过本次运行: %v", err)
+			return 1
+		}
+	}
+
+	if len(cfg.Images()) > 0 {
+		outcome = checkImages(ctx)
+	} else if len(cfg.ContainerNames()) > 0 && cfg.CheckLabel() {
+		outcome = checkContainersByNameAndLabel(ctx)
+	} else if len(cfg.ContainerNames()) > 0 {
+		outcome = checkContainersByName(ctx)
+	} else if len(cfg.Projects()) > 0 {
+		outcome = checkContainersByProject(ctx)
 	} else if cfg.CheckAll() {
-		checkAllContainers(ctx)
+		outcome = checkAllContainers(ctx)
 	} else if cfg.CheckLabelReversed() {
-		checkContainersByLabelReversed(ctx)
+		outcome = checkContainersByLabelReversed(ctx)
 	} else if cfg.CheckLabel() {
-		checkContainersByLabel(ctx)
-	} else {
+		outcome = checkContainersByLabel(ctx)
+	} else if !cfg.SelfUpdate() {
 		config.PrintUsage()
 	}
+
+	// 自我更新成功后当前进程会退出，放在最后执行
+	if cfg.SelfUpdate() && !runSelfUpdate(ctx) {
+		outcome.failed++
+	}
+
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		logger.Error("运行超过 %v 的时间限制，已取消所有进行中的检查和更新", cfg.RunTimeout())
+		outcome.failed++
+	} else if errors.Is(ctx.Err(), context.Canceled) {
+		logger.Warn("收到退出信号，已取消进行中的检查和更新")
+		outcome.failed++
+	}
+
+	exitCode := outcome.exitCode()
+	if cfg.PostRunHook() != "" {
+		// 运行超时后仍需执行运行后钩子（如恢复监控告警），因此不使用已取消的 ctx
+		env := []string{
+			fmt.Sprintf("WATCHDUCKER_UPDATED=%d", outcome.updated),
+			fmt.Sprintf("WATCHDUCKER_FAILED=%d", outcome.failed),
+			fmt.Sprintf("WATCHDUCKER_EXIT_CODE=%d", exitCode),
+		}
+		if err := runHook(context.WithoutCancel(ctx), cfg.PostRunHook(), env); err != nil {
+			logger.Error("运行后钩子执行失败: %v", err)
+		}
+	}
+
+	return exitCode
+}
+
+// runHook 通过 sh -c 执行钩子命令，输出写入日志，env 追加到当前进程的环境变量之后
+func runHook(ctx context.Context, command string, env []string) error {
+	logger.Info("执行钩子: %s", command)
+
+	hook := exec.CommandContext(ctx, "sh", "-c", command)
+	hook.Env = append(os.Environ(), env...)
+	output, err := hook.CombinedOutput()
+	if len(output) > 0 {
+		logger.Info("钩子输出: %s", strings.TrimSpace(string(output)))
+	}
+	return err
+}
+
+// runSelfUpdate 检查并更新 watchducker 自身容器，失败时返回 false
+func runSelfUpdate(ctx context.Context) bool {
+	cfg := config.Get()
+
+	selfUpdater, err := core.NewSelfUpdater("", cfg.SelfUpdateGrace())
+	if err != nil {
+		logger.Error("创建自我更新器失败: %v", err)
+		return false
+	}
+	defer selfUpdater.Close()
+
+	_, err = selfUpdater.SelfUpdate(ctx, func(result *types.ImageCheckResult) {
+		notify.Send(notifyTitle("WatchDucker 自我更新"), fmt.Sprintf("镜像 %s 已更新，新容器已稳定运行", result.Name))
+	})
+	if err != nil {
+		logger.Error("自我更新失败: %v", err)
+		notify.Send(notifyTitle("WatchDucker 自我更新失败"), err.Error())
+		return false
+	}
+	return true
+}
+
+// TestNotify 校验推送配置并发送一条测试通知，返回退出码
+func TestNotify() int {
+	problems := notify.Validate()
+	for _, err := range problems {
+		logger.Error("推送配置有误: %v", err)
+	}
+	if len(problems) > 0 {
+		return 1
+	}
+
+	notify.Send(notifyTitle("WatchDucker 测试通知"), "这是一条测试通知，收到说明推送配置正确")
+	logger.Info("测试通知已发送")
+	return 0
+}
+
+// runMu 避免定时任务与事件触发的检查同时运行
+var runMu sync.Mutex
+
+// recreatedTTL watchducker 重建的容器ID的保留时间，期间这些容器的事件不触发检查
+const recreatedTTL = 5 * time.Minute
+
+// eventKey 待检查容器事件的主机和容器名称
+type eventKey struct {
+	host string
+	name string
+}
+
+var (
+	eventMu sync.Mutex
+	// pendingEvents 检查运行期间收到的容器事件，按主机和名称合并，值为最近一次事件的容器ID
+	pendingEvents = map[eventKey]string{}
+	// recreatedIDs watchducker 更新时新建的容器和更新失败后恢复的旧容器，值为记录时间
+	recreatedIDs = map[string]time.Time{}
+)
+
+// RunEventWatcher 监听每个 Docker 主机的容器 create/start 事件，立即检查对应容器的镜像
+func RunEventWatcher(ctx context.Context) {
+	for _, host := range config.Get().DockerHosts() {
+		watcher, err := core.NewWatcher(host)
+		if err != nil {
+			logger.Error("创建事件监听器失败: %v", err)
+			continue
+		}
+
+		go func(host string) {
+			defer watcher.Close()
+			watcher.Watch(ctx, func(id, name string, labels map[string]string) {
+				if !containerInScope(name, labels) {
+					return
+				}
+				queueContainerEvent(ctx, host, id, name)
+			})
+		}(host)
+	}
+
+	logger.Info("已开始监听 Docker 容器事件")
+}
+
+// queueContainerEvent 记录容器事件并安排检查，已有检查在运行时等其结束后再统一检查
+func queueContainerEvent(ctx context.Context, host, id, name string) {
+	eventMu.Lock()
+	pendingEvents[eventKey{host: host, name: name}] = id
+	eventMu.Unlock()
+
+	go checkPendingEvents(ctx)
+}
+
+// checkPendingEvents 等待当前检查结束后检查所有待处理事件对应的容器，
+// 同一批事件只由最先拿到锁的调用处理，watchducker 自身重建容器产生的事件不再触发检查
+func checkPendingEvents(ctx context.Context) {
+	runMu.Lock()
+	defer runMu.Unlock()
+	defer logger.Recover()
+
+	if ctx.Err() != nil {
+		return
+	}
+
+	eventMu.Lock()
+	events := pendingEvents
+	pendingEvents = map[eventKey]string{}
+	hostNames := make(map[string][]string)
+	for key, id := range events {
+		if _, ok := recreatedIDs[id]; ok {
+			logger.Debug("容器 %s (%s) 由 watchducker 更新时重建，忽略其事件", key.name, utils.ShortID(id))
+			continue
+		}
+		hostNames[key.host] = append(hostNames[key.host], key.name)
+	}
+	eventMu.Unlock()
+
+	cfg := config.Get()
+	for host, names := range hostNames {
+		sort.Strings(names)
+		logger.Info("容器 %s 已启动，开始检查镜像更新", strings.Join(names, ", "))
+		runCheckerOnHost(ctx, host, loadStateStore(), func(checker *core.Checker) (*types.BatchCheckResult, error) {
+			return checker.CheckByName(ctx, names, cfg.DisabledContainers())
+		})
+	}
+}
+
+// recordRecreated 记录本次更新中 watchducker 新建或恢复启动的容器ID，并清理过期记录
+func recordRecreated(updates []types.ContainerUpdateResult) {
+	eventMu.Lock()
+	defer eventMu.Unlock()
+
+	now := time.Now()
+	for id, at := range recreatedIDs {
+		if now.Sub(at) > recreatedTTL {
+			delete(recreatedIDs, id)
+		}
+	}
+	for _, update := range updates {
+		if update.Skipped {
+			continue
+		}
+		// 更新失败时旧容器会被恢复启动，同样会产生事件
+		for _, id := range []string{update.ContainerID, update.NewContainerID} {
+			if id != "" {
+				recreatedIDs[id] = now
+			}
+		}
+	}
+}
+
+// containerInScope 按 RunOnce 的检查模式判断容器是否需要检查，用于事件和 webhook 触发的检查
+func containerInScope(name string, labels map[string]string) bool {
+	labelKey, labelValue := "watchducker.update", "true"
+	cfg := config.Get()
+
+	if name == "" || utils.SliceContains(cfg.DisabledContainers(), name) {
+		return false
+	}
+
+	switch {
+	case len(cfg.ContainerNames()) > 0 && cfg.CheckLabel():
+		return docker.MatchName(cfg.ContainerNames(), name) || labels[labelKey] == labelValue
+	case len(cfg.ContainerNames()) > 0:
+		return docker.MatchName(cfg.ContainerNames(), name)
+	case len(cfg.Projects()) > 0:
+		return utils.SliceContains(cfg.Projects(), labels["com.docker.compose.project"])
+	case cfg.CheckAll():
+		return true
+	case cfg.CheckLabelReversed():
+		return labels[labelKey] != labelValue
+	case cfg.CheckLabel():
+		return labels[labelKey] == labelValue
+	}
+	return false
 }
 
 // RunCronScheduler 运行定时调度器
 func RunCronScheduler(ctx context.Context) {
 	cfg := config.Get()
 
-	// 创建 cron 调度器
-	c := cron.New()
+	// 创建 cron 调度器，按配置的时区解析 cron 表达式
+	c := cron.New(cron.WithLocation(cfg.Location()))
 
 	// 添加定时任务
 	_, err := c.AddFunc(cfg.CronExpression(), func() {
+		// 随机等待一段时间，避免多个实例在同一时刻请求 registry
+		if cfg.Jitter() > 0 {
+			delay := time.Duration(rand.Int63n(int64(cfg.Jitter())))
+			logger.Info("定时任务已触发，随机等待 %v 后执行", delay.Round(time.Second))
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(delay):
+			}
+		}
+
+		runMu.Lock()
+		defer runMu.Unlock()
+		defer logger.Recover()
+
 		logger.Info("定时任务开始执行")
 
 		RunOnce(ctx)
@@ -93,23 +415,105 @@ func RunCronScheduler(ctx context.Context) {
 	// 启动调度器
 	c.Start()
 
-	// 保持程序运行
-	select {}
+	// 运行直到收到退出信号
+	<-ctx.Done()
+	logger.Info("收到退出信号，正在停止定时任务")
+
+	// 等待进行中的定时任务、webhook 和事件触发的检查结束，进行中的拉取会因 ctx 取消而尽快中止
+	<-c.Stop().Done()
+	runMu.Lock()
+	logger.Info("定时任务已停止")
 }
 
-// RunChecker 创建并运行检查器的通用函数
-func RunChecker(ctx context.Context, checkFunc func(*core.Checker) (*types.BatchCheckResult, error)) {
+// RunChecker 对每个 Docker 主机创建并运行检查器的通用函数
+func RunChecker(ctx context.Context, checkFunc func(*core.Checker) (*types.BatchCheckResult, error)) runOutcome {
 	utils.PrintWelcome()
 
 	cfg := config.Get()
+	docker.SetPullRateLimit(cfg.PullRateLimit())
+	docker.SetShowPullProgress(cfg.ShowPullProgress())
+
+	store := loadStateStore()
+
+	var outcome runOutcome
+	for _, host := range cfg.DockerHosts() {
+		outcome.add(runCheckerOnHost(ctx, host, store, checkFunc))
+	}
+
+	// 写入本次运行的 Markdown 报告
+	if cfg.ReportMarkdown() != "" {
+		if err := utils.WriteMarkdownReport(cfg.ReportMarkdown(), outcome.results); err != nil {
+			logger.Warn("写入 Markdown 报告失败: %v", err)
+		}
+	}
+
+	// 导出本次运行的 CSV 检查结果
+	if cfg.CSVFile() != "" {
+		if err := utils.WriteCSV(cfg.CSVFile(), outcome.results); err != nil {
+			logger.Warn("导出 CSV 检查结果失败: %v", err)
+		}
+	}
+	return outcome
+}
+
+// loadStateStore 加载容器状态文件，未配置或加载失败时返回 nil
+func loadStateStore() *state.Store {
+	cfg := config.Get()
+	if cfg.StateFile() == "" {
+		return nil
+	}
+
+	store, err := state.Load(cfg.StateFile())
+	if err != nil {
+		logger.Warn("加载容器状态文件失败，本次不记录状态: %v", err)
+		return nil
+	}
+	docker.LoadDigestCache(store.Images)
+	return store
+}
+
+// runCheckerOnHost 在指定 Docker 主机上运行检查和更新
+func runCheckerOnHost(ctx context.Context, host string, store *state.Store, checkFunc func(*core.Checker) (*types.BatchCheckResult, error)) runOutcome {
+	cfg := config.Get()
+
+	if host != "" {
+		logger.Info("开始检查 Docker 主机: %s", host)
+	}
+
+	// 清理更新中断时遗留的旧容器
+	if cfg.CleanupOrphans() {
+		cleanupOrphans(ctx, host)
+	}
 
 	// 创建检查器
-	checker, err := core.NewChecker(cfg.IncludeStopped())
+	checker, err := core.NewChecker(host, core.CheckerOptions{
+		IncludeStopped:   cfg.IncludeStopped(),
+		CheckTimeout:     cfg.CheckTimeout(),
+		RegistryMirrors:  cfg.RegistryMirrors(),
+		Concurrency:      cfg.CheckConcurrency(),
+		InCooldown:       cooldownFunc(store, host, cfg.Cooldown()),
+		NoPull:           cfg.NoPull(),
+		SkipRegistries:   cfg.SkipRegistries(),
+		MinImageAge:      cfg.MinImageAge(),
+		PullTimeout:      cfg.PullTimeout(),
+		MinUptime:        cfg.MinUptime(),
+		SemverTags:       cfg.SemverTags(),
+		SemverAllowMajor: cfg.SemverAllowMajor(),
+		RegistryLimits:   cfg.ConcurrencyPerRegistry(),
+	})
 	if err != nil {
-		logger.Fatal("创建检查器失败: %v", err)
+		logger.Error("创建检查器失败，跳过 Docker 主机 %s: %v", host, err)
+		return hostFailure(host, fmt.Errorf("创建检查器失败: %w", err))
 	}
 	defer checker.Close()
 
+	// daemon 重启或暂时不可用时先重连等待，而不是直接失败
+	var outcome runOutcome
+	if err := checker.WaitDocker(ctx); err != nil {
+		logger.Error("Docker 服务不可用，跳过本次检查: %v", err)
+		return hostFailure(host, fmt.Errorf("Docker 服务不可用: %w", err))
+	}
+
 	// 使用回调函数实时输出结果
 	result, err := checkFunc(checker)
 	if err != nil {
@@ -117,34 +521,182 @@ func RunChecker(ctx context.Context, checkFunc func(*core.Checker) (*types.Batch
 	}
 
 	if result == nil {
-		return
+		outcome.failed++
+		return outcome
+	}
+	result.Host = host
+	if cfg.SortBy() == "status" {
+		utils.SortByStatus(result)
 	}
+	outcome.updated = result.Summary.Updated
+	outcome.failed = result.Summary.Failed
+	outcome.results = []*types.BatchCheckResult{result}
 
-	if !cfg.NoRestart() && result.Summary.Updated > 0 {
-		// 创建操作器
-		operator, err := core.NewOperator()
-		if err != nil {
-			logger.Fatal("创建操作器失败: %v", err)
+	// --image 模式只检查镜像，没有需要更新的容器
+	if !cfg.NoRestart() && len(cfg.Images()) == 0 && result.Summary.Updated > 0 {
+		outcome.failed += updateContainersOnHost(ctx, host, result)
+	}
+
+	// 不重建容器时按标签热更新资源限制
+	if cfg.NoRestart() {
+		applyResourceLabels(ctx, host, result.Containers)
+	}
+
+	// 输出最终结果
+	utils.PrintHost(result.Host)
+	if len(cfg.Images()) > 0 {
+		utils.PrintImageList(result)
+	} else {
+		utils.PrintContainerList(result)
+	}
+	utils.PrintUpdateResults(result)
+	utils.PrintBatchSummary(result)
+
+	// 记录容器检查和更新状态
+	if store != nil {
+		recordState(store, result)
+		store.Images = docker.DigestCache()
+		if err := store.Save(); err != nil {
+			logger.Warn("保存容器状态文件失败: %v", err)
 		}
-		defer operator.Close()
+	}
 
-		// 更新有镜像更新的容器
-		err = operator.UpdateContainersByBatchCheckResult(ctx, result)
-		if err != nil {
-			logger.Error("容器更新过程中出现错误: %v", err)
+	// 追加写入检查结果报告
+	if cfg.ReportFile() != "" {
+		if err := utils.AppendReport(cfg.ReportFile(), result); err != nil {
+			logger.Warn("写入检查结果报告失败: %v", err)
 		}
+	}
 
-		// 如果启用了清理功能，清理悬空镜像
-		if cfg.CleanUp() {
-			if err := operator.CleanDanglingImages(ctx); err != nil {
-				logger.Error("清理悬空镜像失败: %v", err)
-			}
+	return outcome
+}
+
+// updateContainersOnHost 在指定 Docker 主机上更新有镜像更新的容器，返回失败数量
+func updateContainersOnHost(ctx context.Context, host string, result *types.BatchCheckResult) int {
+	cfg := config.Get()
+
+	// 创建操作器
+	operator, err := core.NewOperator(host, core.OperatorOptions{
+		BackupBeforeUpdate: cfg.BackupBeforeUpdate(),
+		BackupKeep:         cfg.BackupKeep(),
+		Concurrency:        cfg.UpdateConcurrency(),
+		WaitReady:          cfg.WaitReady(),
+		ResetEntrypoint:    !cfg.InheritEntrypoint(),
+		SkipUnhealthy:      cfg.SkipUnhealthy(),
+		CleanUp:            cfg.CleanUp(),
+	})
+	if err != nil {
+		logger.Error("创建操作器失败，跳过 Docker 主机 %s 的容器更新: %v", host, err)
+		result.Error = fmt.Sprintf("创建操作器失败: %v", err)
+		return 1
+	}
+	defer operator.Close()
+
+	// 更新有镜像更新的容器
+	var failed int
+	if err := operator.UpdateContainersByBatchCheckResult(ctx, result); err != nil {
+		logger.Error("容器更新过程中出现错误: %v", err)
+		failed++
+	}
+	recordRecreated(result.Updates)
+
+	// 如果启用了清理功能，清理悬空镜像；有容器通过标签要求保留旧镜像时只按容器清理
+	if cfg.CleanUp() && !core.KeepsOldImages(result) {
+		if err := operator.CleanDanglingImages(ctx); err != nil {
+			logger.Error("清理悬空镜像失败: %v", err)
 		}
+	}
+
+	notify.Send(notifyTitle("WatchDucker 镜像更新"), utils.GetUpdateSummary(result))
 
-		notify.Send("WatchDucker 镜像更新", utils.GetUpdateSummary(result))
+	// 有容器更新失败时额外向失败告警渠道发送
+	if failures := utils.GetFailureSummary(result); failures != "" {
+		notify.SendFailure(notifyTitle("WatchDucker 容器更新失败"), failures)
 	}
+	return failed
+}
 
-	// 输出最终结果
-	utils.PrintContainerList(result.Containers)
-	utils.PrintBatchSummary(result)
+// hostFailure 返回无法检查的主机的结果统计，主机记为一次失败并写入检查结果，便于报告中体现
+func hostFailure(host string, err error) runOutcome {
+	result := &types.BatchCheckResult{Host: host, Error: err.Error()}
+	result.Summary.Failed = 1
+	return runOutcome{failed: 1, results: []*types.BatchCheckResult{result}}
+}
+
+// cleanupOrphans 清理指定主机上更新中断时遗留的旧容器
+func cleanupOrphans(ctx context.Context, host string) {
+	operator, err := core.NewOperator(host, core.OperatorOptions{})
+	if err != nil {
+		logger.Error("创建操作器失败: %v", err)
+		return
+	}
+	defer operator.Close()
+
+	removed, err := operator.CleanupOrphans(ctx)
+	if err != nil {
+		logger.Error("清理遗留旧容器失败: %v", err)
+		return
+	}
+	if removed > 0 {
+		logger.Info("共清理 %d 个遗留旧容器", removed)
+	}
+}
+
+// applyResourceLabels 按容器的资源限制标签热更新指定主机上的容器
+func applyResourceLabels(ctx context.Context, host string, containers []types.ContainerInfo) {
+	operator, err := core.NewOperator(host, core.OperatorOptions{})
+	if err != nil {
+		logger.Error("创建操作器失败: %v", err)
+		return
+	}
+	defer operator.Close()
+
+	updated, err := operator.ApplyResourceLabels(ctx, containers)
+	if err != nil {
+		logger.Warn("按标签更新容器资源限制时出现错误: %v", err)
+	}
+	if updated > 0 {
+		logger.Info("已按标签更新 %d 个容器的资源限制", updated)
+	}
+}
+
+// cooldownFunc 根据状态文件中的更新时间判断容器是否处于冷却期，未启用时返回 nil
+func cooldownFunc(store *state.Store, host string, cooldown time.Duration) func(string) bool {
+	if store == nil || cooldown <= 0 {
+		return nil
+	}
+
+	return func(containerName string) bool {
+		st, ok := store.Get(state.Key(host, containerName))
+		return ok && !st.LastUpdated.IsZero() && time.Since(st.LastUpdated) < cooldown
+	}
+}
+
+// recordState 将本次检查结果写入容器状态，成功更新的容器记录为新镜像的摘要
+func recordState(store *state.Store, result *types.BatchCheckResult) {
+	images := make(map[string]*types.ImageCheckResult, len(result.Images))
+	for _, info := range result.Images {
+		images[info.Name] = info
+	}
+
+	updated := make(map[string]bool, len(result.Updates))
+	for _, update := range result.Updates {
+		updated[update.Name] = update.Success
+	}
+
+	now := time.Now()
+	for _, container := range result.Containers {
+		key := state.Key(result.Host, container.Name)
+		info, ok := images[container.Image]
+		if !ok {
+			continue
+		}
+
+		hash := info.LocalHash
+		if updated[container.Name] {
+			hash = info.RemoteHash
+			store.RecordUpdate(key, now)
+		}
+		store.RecordCheck(key, hash, now)
+	}
 }
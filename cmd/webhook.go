@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"watchducker/internal/core"
+	"watchducker/internal/types"
+	"watchducker/pkg/config"
+	"watchducker/pkg/logger"
+
+	"github.com/distribution/reference"
+)
+
+// hookPayload POST /hook 的请求体
+type hookPayload struct {
+	Image string `json:"image"` // 已推送新版本的镜像，如 nginx:latest
+}
+
+// RunWebhookServer 启动接收更新 webhook 的 HTTP 服务，收到请求后立即检查并更新使用该镜像的容器，
+// 服务异常退出时错误写入返回的 channel，正常停止时 channel 被关闭
+func RunWebhookServer(ctx context.Context) <-chan error {
+	cfg := config.Get()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /hook", func(w http.ResponseWriter, r *http.Request) {
+		handleHook(ctx, w, r)
+	})
+
+	server := &http.Server{Addr: cfg.WebhookListen(), Handler: mux}
+	errs := make(chan error, 1)
+	go func() {
+		defer close(errs)
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			errs <- err
+		}
+	}()
+
+	// 收到退出信号后停止接收新的 webhook
+	go func() {
+		<-ctx.Done()
+		server.Shutdown(context.WithoutCancel(ctx))
+	}()
+
+	logger.Info("webhook 服务已启动，监听地址: %s", cfg.WebhookListen())
+	return errs
+}
+
+// handleHook 校验并解析 webhook 请求，检查在后台执行，请求立即返回 202
+func handleHook(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+	cfg := config.Get()
+
+	if secret := cfg.WebhookSecret(); secret != "" {
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(token), []byte(secret)) != 1 {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+	}
+
+	var payload hookPayload
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&payload); err != nil {
+		http.Error(w, "invalid payload", http.StatusBadRequest)
+		return
+	}
+	if _, err := reference.ParseNormalizedNamed(payload.Image); err != nil {
+		http.Error(w, "invalid image", http.StatusBadRequest)
+		return
+	}
+
+	logger.Info("收到镜像 %s 的更新 webhook", payload.Image)
+	go checkImageOnHook(ctx, payload.Image)
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// checkImageOnHook 在所有主机上检查并更新使用指定镜像的容器，与定时任务串行执行
+func checkImageOnHook(ctx context.Context, image string) {
+	runMu.Lock()
+	defer runMu.Unlock()
+	defer logger.Recover()
+
+	cfg := config.Get()
+	RunChecker(ctx, func(checker *core.Checker) (*types.BatchCheckResult, error) {
+		return checker.CheckByImage(ctx, []string{image}, cfg.DisabledContainers(), func(container types.ContainerInfo) bool {
+			return containerInScope(container.Name, container.Labels)
+		})
+	})
+}
@@ -2,7 +2,9 @@ package core
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"runtime/debug"
 	"strings"
 	"sync"
 	"time"
@@ -11,36 +13,83 @@ import (
 	"watchducker/internal/types"
 	"watchducker/pkg/logger"
 	"watchducker/pkg/utils"
+
+	"github.com/distribution/reference"
 )
 
+const (
+	selfLabel      = "naomi233.watchducker" // 标识 watchducker 自身容器的标签
+	selfRepository = "naomi233/watchducker" // watchducker 官方镜像仓库名
+
+	composeProjectLabel = "com.docker.compose.project" // docker compose 项目标签
+
+	targetImageLabel = "watchducker.target-image" // 检查和更新时改用的镜像，用于受控切换镜像源或 tag
+)
+
+// CheckerOptions 检查器选项
+type CheckerOptions struct {
+	IncludeStopped   bool                            // 检查时包含已停止的容器
+	CheckTimeout     time.Duration                   // 单个镜像检查的超时时间（<=0 表示不限制）
+	RegistryMirrors  []string                        // 镜像拉取重写规则，格式为 原前缀=镜像源前缀
+	Concurrency      int                             // 同时检查的镜像数量上限（<=0 表示不限制）
+	InCooldown       func(containerName string) bool // 判断容器是否处于更新冷却期，nil 表示不启用
+	NoPull           bool                            // 不拉取镜像，仅比对容器镜像与本地同名镜像
+	SkipRegistries   []string                        // 忽略来自这些 registry 的镜像
+	MinImageAge      time.Duration                   // 新镜像创建时间需超过该时长才触发更新（<=0 表示不限制）
+	PullTimeout      time.Duration                   // 单次镜像拉取的超时时间（<=0 表示不限制）
+	MinUptime        time.Duration                   // 运行中的容器需连续运行超过该时长才参与检查（<=0 表示不限制）
+	SemverTags       bool                            // 版本号 tag 的镜像检查是否有更高版本的 tag
+	SemverAllowMajor bool                            // 检查更高版本的 tag 时是否允许跨主版本
+	RegistryLimits   map[string]int                  // 各 registry 同时检查的镜像数量上限，未列出的 registry 只受 Concurrency 限制
+}
+
 // Checker 核心检查器
 type Checker struct {
 	clientManager  *docker.ClientManager
 	containerSvc   *docker.ContainerService
 	imageSvc       *docker.ImageService
 	includeStopped bool
+	checkTimeout   time.Duration
+	concurrency    int
+	inCooldown     func(containerName string) bool
+	noPull         bool
+	skipRegistries []string
+	minUptime      time.Duration
+	registryLimits map[string]int
 }
 
-// NewChecker 创建新的检查器实例
-func NewChecker(includeStopped bool) (*Checker, error) {
-	clientManager, err := docker.NewClientManager()
+// NewChecker 创建新的检查器实例，dockerHost 为空时使用环境变量中的 Docker 地址
+func NewChecker(dockerHost string, opts CheckerOptions) (*Checker, error) {
+	clientManager, err := docker.NewClientManager(dockerHost)
 	if err != nil {
 		return nil, fmt.Errorf("创建 Docker 客户端管理器失败: %w", err)
 	}
 
 	containerSvc := docker.NewContainerService(clientManager)
 	imageSvc := docker.NewImageService(clientManager)
+	imageSvc.SetMirrors(opts.RegistryMirrors)
+	imageSvc.SetMinImageAge(opts.MinImageAge)
+	imageSvc.SetPullTimeout(opts.PullTimeout)
+	imageSvc.SetSemverTags(opts.SemverTags)
+	imageSvc.SetSemverAllowMajor(opts.SemverAllowMajor)
 
 	return &Checker{
 		clientManager:  clientManager,
 		containerSvc:   containerSvc,
 		imageSvc:       imageSvc,
-		includeStopped: includeStopped,
+		includeStopped: opts.IncludeStopped,
+		checkTimeout:   opts.CheckTimeout,
+		concurrency:    opts.Concurrency,
+		inCooldown:     opts.InCooldown,
+		noPull:         opts.NoPull,
+		skipRegistries: opts.SkipRegistries,
+		minUptime:      opts.MinUptime,
+		registryLimits: opts.RegistryLimits,
 	}, nil
 }
 
-// CheckByName 根据容器名称检查镜像更新
-func (c *Checker) CheckByName(ctx context.Context, containerNames []string) (*types.BatchCheckResult, error) {
+// CheckByName 根据容器名称检查镜像更新，名称支持通配符
+func (c *Checker) CheckByName(ctx context.Context, containerNames []string, disabledContainers []string) (*types.BatchCheckResult, error) {
 	logger.Info("开始根据容器名称检查镜像更新: %v", containerNames)
 
 	// 获取所有指定名称的容器
@@ -49,8 +98,18 @@ func (c *Checker) CheckByName(ctx context.Context, containerNames []string) (*ty
 		return nil, fmt.Errorf("获取容器失败: %w", err)
 	}
 
+	// 通配符可能匹配到被排除的容器，需要再次过滤
+	filteredContainers := make([]types.ContainerInfo, 0, len(containers))
+	for _, container := range containers {
+		if !utils.SliceContains(disabledContainers, container.Name) {
+			filteredContainers = append(filteredContainers, container)
+		} else {
+			logger.Info("跳过被排除的容器: %s", container.Name)
+		}
+	}
+
 	// 使用通用检查逻辑
-	return c.checkImages(ctx, containers, utils.CreateCheckCallback())
+	return c.checkImages(ctx, filteredContainers, utils.CreateCheckCallback())
 }
 
 // CheckByLabel 根据标签检查镜像更新
@@ -78,6 +137,66 @@ func (c *Checker) CheckByLabel(ctx context.Context, labelKey, labelValue string,
 	return c.checkImages(ctx, filteredContainers, utils.CreateCheckCallback())
 }
 
+// CheckByNameAndLabel 检查指定名称的容器以及带有指定标签的容器，两者取并集
+func (c *Checker) CheckByNameAndLabel(ctx context.Context, containerNames []string, labelKey, labelValue string, disabledContainers []string) (*types.BatchCheckResult, error) {
+	logger.Info("开始检查指定容器 %v 以及带有标签 %s=%s 的容器", containerNames, labelKey, labelValue)
+	logger.Info("被排除的容器: %v", disabledContainers)
+
+	byName, err := c.containerSvc.GetByName(ctx, containerNames, c.includeStopped)
+	if err != nil {
+		return nil, fmt.Errorf("获取容器失败: %w", err)
+	}
+
+	byLabel, err := c.containerSvc.GetByLabel(ctx, labelKey, labelValue, c.includeStopped)
+	if err != nil {
+		return nil, fmt.Errorf("获取标签容器失败: %w", err)
+	}
+
+	// 合并两组容器，按容器ID去重并过滤掉被排除的容器
+	seen := make(map[string]struct{})
+	var containers []types.ContainerInfo
+	for _, container := range append(byName, byLabel...) {
+		if _, ok := seen[container.ID]; ok {
+			continue
+		}
+		seen[container.ID] = struct{}{}
+
+		if utils.SliceContains(disabledContainers, container.Name) {
+			logger.Info("跳过被排除的容器: %s", container.Name)
+			continue
+		}
+		containers = append(containers, container)
+	}
+
+	// 使用通用检查逻辑
+	return c.checkImages(ctx, containers, utils.CreateCheckCallback())
+}
+
+// CheckByProject 检查属于指定 compose 项目的容器
+func (c *Checker) CheckByProject(ctx context.Context, projects []string, disabledContainers []string) (*types.BatchCheckResult, error) {
+	logger.Info("开始检查 compose 项目的容器: %v", projects)
+	logger.Info("被排除的容器: %v", disabledContainers)
+
+	var containers []types.ContainerInfo
+	for _, project := range projects {
+		projectContainers, err := c.containerSvc.GetByLabel(ctx, composeProjectLabel, project, c.includeStopped)
+		if err != nil {
+			return nil, fmt.Errorf("获取项目 %s 的容器失败: %w", project, err)
+		}
+
+		for _, container := range projectContainers {
+			if utils.SliceContains(disabledContainers, container.Name) {
+				logger.Info("跳过被排除的容器: %s", container.Name)
+				continue
+			}
+			containers = append(containers, container)
+		}
+	}
+
+	// 使用通用检查逻辑
+	return c.checkImages(ctx, containers, utils.CreateCheckCallback())
+}
+
 // CheckByLabelReversed 检查没有传入标签的容器
 func (c *Checker) CheckByLabelReversed(ctx context.Context, labelKey, labelValue string, disabledContainers []string) (*types.BatchCheckResult, error) {
 	logger.Info("开始检查没有 %s=%s 标签的容器", labelKey, labelValue)
@@ -138,9 +257,60 @@ func (c *Checker) CheckAll(ctx context.Context, disabledContainers []string) (*t
 	return c.checkImages(ctx, filteredContainers, utils.CreateCheckCallback())
 }
 
+// CheckByImage 检查使用指定镜像的容器，inScope 不为 nil 时只检查其返回 true 的容器
+func (c *Checker) CheckByImage(ctx context.Context, images []string, disabledContainers []string, inScope func(types.ContainerInfo) bool) (*types.BatchCheckResult, error) {
+	logger.Info("开始检查使用镜像 %v 的容器", images)
+	logger.Info("被排除的容器: %v", disabledContainers)
+
+	targets := make(map[string]struct{}, len(images))
+	for _, image := range images {
+		targets[canonicalImage(image)] = struct{}{}
+	}
+
+	// 获取所有容器
+	containers, err := c.containerSvc.GetAll(ctx, c.includeStopped)
+	if err != nil {
+		return nil, fmt.Errorf("获取所有容器失败: %w", err)
+	}
+
+	filteredContainers := make([]types.ContainerInfo, 0, len(containers))
+	for _, container := range containers {
+		imageRef, err := c.resolveImageReference(ctx, container)
+		if err != nil {
+			continue
+		}
+		if _, ok := targets[canonicalImage(imageRef)]; !ok {
+			continue
+		}
+
+		if utils.SliceContains(disabledContainers, container.Name) {
+			logger.Info("跳过被排除的容器: %s", container.Name)
+			continue
+		}
+		if inScope != nil && !inScope(container) {
+			continue
+		}
+		filteredContainers = append(filteredContainers, container)
+	}
+
+	// 使用通用检查逻辑
+	return c.checkImages(ctx, filteredContainers, utils.CreateCheckCallback())
+}
+
+// canonicalImage 将镜像引用规范化为完整形式（补全 docker.io 和 latest 标签），便于比较不同写法的同一镜像
+func canonicalImage(imageRef string) string {
+	named, err := reference.ParseNormalizedNamed(imageRef)
+	if err != nil {
+		return imageRef
+	}
+	return reference.TagNameOnly(named).String()
+}
+
 // checkImages 通用的镜像检查逻辑
 func (c *Checker) checkImages(ctx context.Context, containers []types.ContainerInfo, callback types.CheckCallback) (*types.BatchCheckResult, error) {
 	startTime := time.Now()
+	containers = c.filterCooldown(containers)
+	containers = c.filterUptime(ctx, containers)
 	result := &types.BatchCheckResult{
 		Containers: containers,
 	}
@@ -155,15 +325,68 @@ func (c *Checker) checkImages(ctx context.Context, containers []types.ContainerI
 
 	// 提取唯一的镜像名称
 	imageNames, skipped := c.extractImageReferences(ctx, containers)
+	result.Summary.TotalImages = len(imageNames) + len(skipped)
 	result.Images = append(result.Images, skipped...)
 	if callback != nil {
-		for _, skippedResult := range skipped {
-			callback(skippedResult)
+		for i, skippedResult := range skipped {
+			callback(skippedResult, i+1, result.Summary.TotalImages)
 		}
 	}
-	result.Summary.TotalImages = len(imageNames) + len(skipped)
 	logger.Debug("提取到 %d 个可检查镜像: %v", len(imageNames), imageNames)
 
+	return c.checkImageNames(ctx, result, imageNames, callback, startTime)
+}
+
+// CheckImages 直接检查指定镜像是否有更新，不查找使用这些镜像的容器，结果中不包含容器
+func (c *Checker) CheckImages(ctx context.Context, images []string) (*types.BatchCheckResult, error) {
+	logger.Info("开始检查指定镜像: %v", images)
+	startTime := time.Now()
+	result := &types.BatchCheckResult{}
+
+	seen := make(map[string]struct{}, len(images))
+	var imageNames []string
+	for _, image := range images {
+		normalized, err := c.imageSvc.NormalizeReference(ctx, image)
+		if err != nil {
+			msg := fmt.Sprintf("镜像 %s 无法解析: %v", image, err)
+			logger.Warn("%s", msg)
+			result.Images = append(result.Images, &types.ImageCheckResult{
+				Name:      image,
+				Error:     msg,
+				CheckedAt: time.Now(),
+			})
+			continue
+		}
+		if _, ok := seen[normalized]; ok {
+			continue
+		}
+		seen[normalized] = struct{}{}
+		imageNames = append(imageNames, normalized)
+	}
+	result.Summary.TotalImages = len(imageNames) + len(result.Images)
+	callback := utils.CreateCheckCallback()
+	for i, failed := range result.Images {
+		callback(failed, i+1, result.Summary.TotalImages)
+	}
+
+	return c.checkImageNames(ctx, result, imageNames, callback, startTime)
+}
+
+// checkImageNames 并发检查镜像并汇总到 result，result 中的容器用于判断 --no-pull 和切换目标镜像时是否需要重建
+func (c *Checker) checkImageNames(ctx context.Context, result *types.BatchCheckResult, imageNames []string, callback types.CheckCallback, startTime time.Time) (*types.BatchCheckResult, error) {
+	containers := result.Containers
+
+	// 记录每个镜像被容器实际使用的镜像ID，--no-pull 模式下据此判断是否需要重建
+	// 通过 watchducker.target-image 切换镜像的容器单独记录，目标镜像本身无更新时也需要据此判断是否重建
+	containerImageIDs := make(map[string][]string)
+	switchContainers := make(map[string][]types.ContainerInfo)
+	for _, container := range containers {
+		containerImageIDs[container.Image] = append(containerImageIDs[container.Image], container.ImageID)
+		if container.Labels[targetImageLabel] != "" {
+			switchContainers[container.Image] = append(switchContainers[container.Image], container)
+		}
+	}
+
 	// 并发检查所有镜像
 	var wg sync.WaitGroup
 	resultsChan := make(chan *types.ImageCheckResult, len(imageNames))
@@ -171,19 +394,70 @@ func (c *Checker) checkImages(ctx context.Context, containers []types.ContainerI
 
 	logger.Debug("开始并发检查 %d 个镜像", len(imageNames))
 
+	// 限制同时检查的镜像数量，配置了 registry 并发上限的镜像还需获取对应 registry 的令牌
+	var sem chan struct{}
+	if c.concurrency > 0 {
+		sem = make(chan struct{}, c.concurrency)
+	}
+	registrySems := make(map[string]chan struct{}, len(c.registryLimits))
+	for registry, limit := range c.registryLimits {
+		registrySems[registry] = make(chan struct{}, limit)
+	}
+
 	for _, imageName := range imageNames {
 		wg.Add(1)
 		go func(name string) {
 			defer wg.Done()
 
+			// 先获取 registry 令牌再获取全局令牌，避免等待受限 registry 的检查占用全局名额
+			for _, s := range []chan struct{}{registrySems[registryHost(name)], sem} {
+				if s == nil {
+					continue
+				}
+				select {
+				case s <- struct{}{}:
+					defer func() { <-s }()
+				case <-ctx.Done():
+					// 已取消时不再开始排队中的检查
+					resultsChan <- &types.ImageCheckResult{
+						Name:      name,
+						Reason:    types.ReasonRemoteError,
+						Error:     fmt.Sprintf("检查已取消: %v", ctx.Err()),
+						CheckedAt: time.Now(),
+					}
+					return
+				}
+			}
+
+			// 单个镜像检查 panic 时记为该镜像检查失败，不影响其他镜像和守护进程
+			defer func() {
+				if r := recover(); r != nil {
+					logger.Error("检查镜像 %s 时发生 panic: %v\n%s", name, r, debug.Stack())
+					errChan <- fmt.Errorf("检查镜像 %s 失败: panic: %v", name, r)
+					resultsChan <- &types.ImageCheckResult{
+						Name:      name,
+						Error:     fmt.Sprintf("panic: %v", r),
+						CheckedAt: time.Now(),
+					}
+				}
+			}()
+
 			logger.Info("开始检查镜像: %s", name)
-			info, err := c.imageSvc.CheckUpdate(ctx, name)
+			info, err := c.checkImage(ctx, name, containerImageIDs[name])
 			if err != nil {
-				logger.Debug("检查镜像 %s 失败: %v", name, err)
-				errChan <- fmt.Errorf("检查镜像 %s 失败: %w", name, err)
+				// registry 不可达只是暂时无法确认更新，不作为检查错误返回
+				if info.Reason == types.ReasonRemoteError {
+					logger.Warn("无法确认镜像 %s 是否有更新: %v", name, err)
+				} else {
+					logger.Debug("检查镜像 %s 失败: %v", name, err)
+					errChan <- fmt.Errorf("检查镜像 %s 失败: %w", name, err)
+				}
 				resultsChan <- info
 				return
 			}
+			if !c.noPull && !info.IsUpdated && len(switchContainers[name]) > 0 {
+				c.markImageSwitch(ctx, info, switchContainers[name])
+			}
 			logger.Debug("镜像 %s 检查完成，是否有更新: %v", name, info.IsUpdated)
 			resultsChan <- info
 		}(imageName)
@@ -201,23 +475,27 @@ func (c *Checker) checkImages(ctx context.Context, containers []types.ContainerI
 		result.Images = append(result.Images, info)
 		// 如果有回调函数，立即调用
 		if callback != nil {
-			callback(info)
+			callback(info, len(result.Images), result.Summary.TotalImages)
 		}
 	}
 
 	// 处理错误
-	var errors []error
+	var errs []error
 	for err := range errChan {
-		errors = append(errors, err)
+		errs = append(errs, err)
 	}
 
 	// 生成统计信息
 	result.Summary.Duration = time.Since(startTime)
 
 	for _, info := range result.Images {
-		if info.Error != "" {
+		if info.Reason == types.ReasonRemoteError {
+			result.Summary.Unknown++
+		} else if info.Error != "" {
 			result.Summary.Failed++
-		} else if info.IsUpdated {
+		} else if info.Reason == types.ReasonPinned || info.Reason == types.ReasonLocalOnly || info.Reason == types.ReasonRegistrySkipped || info.Reason == types.ReasonTooNew {
+			result.Summary.Skipped++
+		} else if info.NeedsUpdate() {
 			result.Summary.Updated++
 		} else {
 			result.Summary.UpToDate++
@@ -225,26 +503,128 @@ func (c *Checker) checkImages(ctx context.Context, containers []types.ContainerI
 	}
 
 	// 记录检查结果
-	logger.Info("镜像检查完成: 更新 %d, 最新 %d, 失败 %d, 耗时 %v",
-		result.Summary.Updated, result.Summary.UpToDate, result.Summary.Failed, result.Summary.Duration)
+	logger.Info("镜像检查完成: 更新 %d, 最新 %d, 跳过 %d, 未知 %d, 失败 %d, 耗时 %v",
+		result.Summary.Updated, result.Summary.UpToDate, result.Summary.Skipped, result.Summary.Unknown, result.Summary.Failed, result.Summary.Duration)
 
-	// 如果有错误，返回第一个错误
-	if len(errors) > 0 {
-		logger.Warn("检查过程中出现 %d 个错误", len(errors))
-		return result, errors[0]
+	// 如果有错误，聚合返回所有错误
+	if len(errs) > 0 {
+		logger.Warn("检查过程中出现 %d 个错误", len(errs))
+		return result, errors.Join(errs...)
 	}
 
 	return result, nil
 }
 
-// extractImageReferences 提取容器中的唯一镜像引用
+// checkImage 在超时控制下检查单个镜像
+func (c *Checker) checkImage(ctx context.Context, name string, containerImageIDs []string) (*types.ImageCheckResult, error) {
+	check := c.imageSvc.CheckUpdate
+	if c.noPull {
+		check = func(ctx context.Context, name string) (*types.ImageCheckResult, error) {
+			return c.imageSvc.CheckLocalUpdate(ctx, name, containerImageIDs)
+		}
+	}
+
+	if c.checkTimeout <= 0 {
+		return check(ctx, name)
+	}
+
+	checkCtx, cancel := context.WithTimeout(ctx, c.checkTimeout)
+	defer cancel()
+
+	info, err := check(checkCtx, name)
+	if err != nil && errors.Is(checkCtx.Err(), context.DeadlineExceeded) {
+		err = fmt.Errorf("检查超时（%v）: %w", c.checkTimeout, err)
+		info.Error = err.Error()
+	}
+	return info, err
+}
+
+// markImageSwitch 容器通过 watchducker.target-image 指定的目标镜像已是最新，但容器仍在使用其他镜像时，
+// 将这些容器记录到检查结果中以触发切换，镜像本身不标记为有更新，使用同一镜像的其他容器不会被重建。
+// --no-pull 模式下 CheckLocalUpdate 已按镜像ID比对，无需处理
+func (c *Checker) markImageSwitch(ctx context.Context, info *types.ImageCheckResult, containers []types.ContainerInfo) {
+	if info.Reason != "" && info.Reason != types.ReasonLocalPulled {
+		return
+	}
+
+	imageInfo, err := c.containerSvc.GetImageInspect(ctx, info.Name)
+	if err != nil {
+		logger.Debug("获取目标镜像 %s 信息失败，跳过切换判断: %v", info.Name, err)
+		return
+	}
+
+	for _, container := range containers {
+		if container.ImageID != imageInfo.ID {
+			logger.Info("容器 %s 尚未切换到目标镜像 %s，标记为需要更新", container.Name, info.Name)
+			info.SwitchContainers = append(info.SwitchContainers, container.ID)
+		}
+	}
+	if len(info.SwitchContainers) > 0 {
+		info.Reason = ""
+	}
+}
+
+// filterCooldown 过滤掉处于更新冷却期内的容器
+func (c *Checker) filterCooldown(containers []types.ContainerInfo) []types.ContainerInfo {
+	if c.inCooldown == nil {
+		return containers
+	}
+
+	var filtered []types.ContainerInfo
+	for _, container := range containers {
+		if c.inCooldown(container.Name) {
+			logger.Info("容器 %s 最近已更新，处于冷却期内，跳过检查", container.Name)
+			continue
+		}
+		filtered = append(filtered, container)
+	}
+	return filtered
+}
+
+// filterUptime 过滤掉运行时长未达到 minUptime 的容器，避免更新刚启动或反复重启的容器；
+// 已停止的容器（--include-stopped）不受影响，正在重启的容器始终跳过
+func (c *Checker) filterUptime(ctx context.Context, containers []types.ContainerInfo) []types.ContainerInfo {
+	if c.minUptime <= 0 {
+		return containers
+	}
+
+	var filtered []types.ContainerInfo
+	for _, container := range containers {
+		switch container.State {
+		case "running", "paused":
+		case "restarting":
+			logger.Info("容器 %s 正在重启，跳过检查", container.Name)
+			continue
+		default:
+			filtered = append(filtered, container)
+			continue
+		}
+
+		startedAt, err := c.containerSvc.GetStartedAt(ctx, container.ID)
+		if err != nil {
+			logger.Warn("获取容器 %s 的启动时间失败，跳过检查: %v", container.Name, err)
+			continue
+		}
+		container.StartedAt = startedAt
+
+		if uptime := time.Since(startedAt); uptime < c.minUptime {
+			logger.Info("容器 %s 已运行 %v，未达到最短运行时长 %v，跳过检查", container.Name, uptime.Round(time.Second), c.minUptime)
+			continue
+		}
+		filtered = append(filtered, container)
+	}
+	return filtered
+}
+
+// extractImageReferences 提取容器中的唯一镜像引用，并将容器的 Image 字段改写为实际检查的引用
 func (c *Checker) extractImageReferences(ctx context.Context, containers []types.ContainerInfo) ([]string, []*types.ImageCheckResult) {
 	imageSet := make(map[string]struct{})
 	var images []string
 	var skipped []*types.ImageCheckResult
 
-	for _, container := range containers {
-		normalized, err := c.imageSvc.NormalizeReference(ctx, container.Image)
+	for i := range containers {
+		container := &containers[i]
+		normalized, err := c.resolveImageReference(ctx, *container)
 		if err != nil {
 			msg := fmt.Sprintf("容器 %s 的镜像 %s 无法解析: %v", container.Name, container.Image, err)
 			logger.Warn("%s", msg)
@@ -256,9 +636,34 @@ func (c *Checker) extractImageReferences(ctx context.Context, containers []types
 			continue
 		}
 
-		// 忽略自身镜像更新检查
-		if normalized == "naomi233/watchducker" || strings.Contains(normalized, "naomi233/watchducker:") {
-			logger.Info("忽略自身镜像检查: %s (容器: %s)", normalized, container.Name)
+		// digest 固定的镜像除非修改 digest 否则不会有更新，直接跳过
+		if strings.Contains(normalized, "@sha256:") {
+			logger.Info("容器 %s 的镜像 %s 通过 digest 固定，跳过检查", container.Name, normalized)
+			skipped = append(skipped, &types.ImageCheckResult{
+				Name:      normalized,
+				Reason:    types.ReasonPinned,
+				CheckedAt: time.Now(),
+			})
+			continue
+		}
+
+		// 由其他系统管理的 registry 上的镜像不做检查
+		if registry := registryHost(normalized); utils.SliceContains(c.skipRegistries, registry) {
+			logger.Info("容器 %s 的镜像 %s 来自被忽略的 registry %s，跳过检查", container.Name, normalized, registry)
+			skipped = append(skipped, &types.ImageCheckResult{
+				Name:      normalized,
+				Reason:    types.ReasonRegistrySkipped,
+				CheckedAt: time.Now(),
+			})
+			continue
+		}
+
+		// 记录实际检查的引用，更新阶段据此匹配容器
+		container.Image = normalized
+
+		// 忽略自身镜像更新检查，自身容器只通过 SelfUpdater 的安全流程更新
+		if isSelf, reason := isSelfContainer(*container, normalized); isSelf {
+			logger.Info("忽略自身镜像检查: %s (容器: %s，%s)，自身仅通过自我更新流程更新", normalized, container.Name, reason)
 			continue
 		}
 
@@ -273,6 +678,62 @@ func (c *Checker) extractImageReferences(ctx context.Context, containers []types
 	return images, skipped
 }
 
+// resolveImageReference 确定容器实际对应的镜像引用，标签 watchducker.target-image 优先。
+// 镜像被重新打标签后容器的 Image 字段会变成镜像ID，此时优先使用容器创建时指定的 Config.Image
+func (c *Checker) resolveImageReference(ctx context.Context, container types.ContainerInfo) (string, error) {
+	// 标签指定了目标镜像时改用目标镜像检查和更新
+	if target := container.Labels[targetImageLabel]; target != "" {
+		logger.Debug("容器 %s 通过标签 %s 指定目标镜像 %s", container.Name, targetImageLabel, target)
+		return c.imageSvc.NormalizeReference(ctx, target)
+	}
+
+	imageRef := container.Image
+	if strings.HasPrefix(imageRef, "sha256:") || imageRef == "<none>:<none>" {
+		containerJSON, err := c.containerSvc.GetContainerConfig(ctx, container.ID)
+		if err != nil {
+			logger.Debug("获取容器 %s 配置失败，改为根据镜像ID解析引用: %v", container.Name, err)
+		} else if containerJSON.Config != nil {
+			if configured := containerJSON.Config.Image; configured != "" && !strings.HasPrefix(configured, "sha256:") {
+				logger.Debug("容器 %s 的镜像字段为镜像ID，使用创建时指定的引用 %s", container.Name, configured)
+				imageRef = configured
+			}
+		}
+	}
+
+	return c.imageSvc.NormalizeReference(ctx, imageRef)
+}
+
+// registryHost 解析镜像引用所在的 registry 主机，Docker Hub 镜像为 docker.io
+func registryHost(imageRef string) string {
+	named, err := reference.ParseNormalizedNamed(imageRef)
+	if err != nil {
+		return ""
+	}
+	return reference.Domain(named)
+}
+
+// isSelfContainer 判断容器是否为 watchducker 自身，优先依据标签，镜像仓库名精确匹配作为兜底
+func isSelfContainer(container types.ContainerInfo, imageRef string) (bool, string) {
+	if container.Labels[selfLabel] == "true" {
+		return true, fmt.Sprintf("命中标签 %s=true", selfLabel)
+	}
+
+	named, err := reference.ParseNormalizedNamed(imageRef)
+	if err != nil {
+		return false, ""
+	}
+	if reference.FamiliarName(named) == selfRepository {
+		return true, fmt.Sprintf("镜像仓库名为 %s", selfRepository)
+	}
+
+	return false, ""
+}
+
+// WaitDocker 确认 Docker 服务可用，daemon 重启或暂时不可用时重连并退避重试
+func (c *Checker) WaitDocker(ctx context.Context) error {
+	return c.clientManager.WaitReady(ctx)
+}
+
 // Close 关闭所有资源
 func (c *Checker) Close() error {
 	var errors []error
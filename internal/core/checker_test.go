@@ -0,0 +1,42 @@
+package core
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"watchducker/internal/docker/dockertest"
+	"watchducker/internal/types"
+
+	dockerTypes "github.com/docker/docker/api/types"
+)
+
+func TestMarkImageSwitch(t *testing.T) {
+	host := dockertest.NewServer(t, map[string]http.HandlerFunc{
+		"GET /images/{name}/json": func(w http.ResponseWriter, r *http.Request) {
+			dockertest.WriteJSON(w, http.StatusOK, dockerTypes.ImageInspect{ID: "sha256:target"})
+		},
+	})
+	checker, err := NewChecker(host, CheckerOptions{})
+	if err != nil {
+		t.Fatalf("创建检查器失败: %v", err)
+	}
+	defer checker.Close()
+
+	info := &types.ImageCheckResult{Name: "app:stable"}
+	checker.markImageSwitch(context.Background(), info, []types.ContainerInfo{
+		{ID: "old1", Name: "app-1", ImageID: "sha256:old"},
+		{ID: "done", Name: "app-2", ImageID: "sha256:target"},
+		{ID: "old2", Name: "app-3", ImageID: "sha256:older"},
+	})
+
+	if info.IsUpdated {
+		t.Error("切换目标镜像不应将镜像本身标记为有更新")
+	}
+	if len(info.SwitchContainers) != 2 || info.SwitchContainers[0] != "old1" || info.SwitchContainers[1] != "old2" {
+		t.Errorf("SwitchContainers = %v, want [old1 old2]", info.SwitchContainers)
+	}
+	if !info.NeedsUpdate() {
+		t.Error("有待切换的容器时 NeedsUpdate 应为 true")
+	}
+}
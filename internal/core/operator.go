@@ -2,28 +2,65 @@ package core
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
+	"regexp"
+	"runtime/debug"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"watchducker/internal/docker"
 	"watchducker/internal/types"
 	"watchducker/pkg/logger"
+	"watchducker/pkg/utils"
 
 	dockerTypes "github.com/docker/docker/api/types"
-	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/api/types/container"
+	units "github.com/docker/go-units"
 )
 
+const (
+	backupLabel  = "watchducker.backup"  // 启用更新前备份的容器标签
+	cpusLabel    = "watchducker.cpus"    // 期望的 CPU 限制，如 1.5
+	memoryLabel  = "watchducker.memory"  // 期望的内存限制，如 512m
+	cleanupLabel = "watchducker.cleanup" // 更新后是否清理被替换的旧镜像（true/false），优先于 --clean
+
+	healthcheckURLLabel = "watchducker.post-update-healthcheck-url" // 更新后探测的 HTTP 健康检查地址
+)
+
+const (
+	probeAttempts = 10              // 健康检查地址的最多探测次数
+	probeInterval = 3 * time.Second // 两次探测之间的间隔
+	probeTimeout  = 5 * time.Second // 单次探测的超时时间
+)
+
+// OperatorOptions 更新器选项
+type OperatorOptions struct {
+	BackupBeforeUpdate bool          // 更新前将旧容器提交为备份镜像
+	BackupKeep         int           // 每个容器保留的备份镜像数量
+	Concurrency        int           // 同时重建的容器数量上限（<=1 表示串行）
+	WaitReady          time.Duration // 启动新容器后等待其就绪的最长时间（<=0 表示不等待）
+	ResetEntrypoint    bool          // 使用新镜像默认的 entrypoint 和 healthcheck，不继承容器的显式覆盖
+	SkipUnhealthy      bool          // 跳过当前非健康状态（unhealthy/starting/restarting）的容器
+	CleanUp            bool          // 未设置 watchducker.cleanup 标签的容器更新后清理被替换的旧镜像
+}
+
 // Operator 容器自动更新器
 type Operator struct {
 	clientManager   *docker.ClientManager
 	containerSvc    *docker.ContainerService
 	containerOpsSvc *docker.ContainerService
 	imageSvc        *docker.ImageService
+	opts            OperatorOptions
 }
 
-// NewOperator 创建新的更新器实例
-func NewOperator() (*Operator, error) {
-	clientManager, err := docker.NewClientManager()
+// NewOperator 创建新的更新器实例，dockerHost 为空时使用环境变量中的 Docker 地址
+func NewOperator(dockerHost string, opts OperatorOptions) (*Operator, error) {
+	clientManager, err := docker.NewClientManager(dockerHost)
 	if err != nil {
 		return nil, fmt.Errorf("创建 Docker 客户端管理器失败: %w", err)
 	}
@@ -37,99 +74,421 @@ func NewOperator() (*Operator, error) {
 		containerSvc:    containerSvc,
 		containerOpsSvc: containerOpsSvc,
 		imageSvc:        imageSvc,
+		opts:            opts,
 	}, nil
 }
 
-// createNewContainer 使用新镜像创建新容器
-func (u *Operator) createNewContainer(ctx context.Context, containerJSON *dockerTypes.ContainerJSON, imageInfo *dockerTypes.ImageInspect, newImage string, containerName string) (string, error) {
-	// 准备创建容器的配置
-	config := u.containerSvc.GetCreateConfig(ctx, *containerJSON, imageInfo, newImage)
-	hostConfig := u.containerSvc.GetCreateHostConfig(ctx, *containerJSON)
-	networkingConfig := u.containerSvc.GetNetworkConfig(ctx, *containerJSON)
+// UpdateContainer 更新容器到新镜像，返回新镜像ID和新容器ID
+// 先保留旧容器，新容器创建并启动成功后才删除旧容器，任一步骤失败都会恢复旧容器
+func (u *Operator) updateContainer(ctx context.Context, containerInfo types.ContainerInfo, newImage string) (string, string, error) {
+	logger.Info("开始更新容器 %s (%s) 到新镜像 %s", containerInfo.Name, containerInfo.ID, newImage)
 
-	// 仅使用一个网络配置来创建容器，之后再连接其他网络
-	simpleNetworkConfig := func() *network.NetworkingConfig {
-		oneEndpoint := make(map[string]*network.EndpointSettings)
-		for k, v := range networkingConfig.EndpointsConfig {
-			oneEndpoint[k] = v
-			break
+	// 1. 获取容器完整配置
+	containerConfig, err := u.containerOpsSvc.GetContainerConfig(ctx, containerInfo.ID)
+	if err != nil {
+		return "", "", fmt.Errorf("获取容器配置失败: %w", err)
+	}
+
+	// 获取新镜像信息
+	imageInfo, err := u.containerOpsSvc.GetImageInspect(ctx, newImage)
+	if err != nil {
+		return "", "", fmt.Errorf("获取镜像信息失败: %w", err)
+	}
+
+	shouldStart := !isStoppedState(containerInfo.State)
+
+	// paused 容器无法正常停止，先恢复运行，更新完成后再重新暂停
+	if containerInfo.State == pausedState {
+		logger.Info("容器 %s 处于暂停状态，先恢复运行再更新", containerInfo.Name)
+		if err := u.containerOpsSvc.UnpauseContainer(ctx, containerInfo.ID); err != nil {
+			return "", "", fmt.Errorf("恢复暂停的容器失败: %w", err)
 		}
-		return &network.NetworkingConfig{EndpointsConfig: oneEndpoint}
-	}()
+	}
 
-	// 创建新容器
-	newContainerID, err := u.containerOpsSvc.CreateContainer(ctx, config, hostConfig, simpleNetworkConfig, containerName)
+	// 2. 停止容器
+	stopTimeout := 30 * time.Second
+	if err := u.containerOpsSvc.StopContainer(ctx, containerInfo.ID, &stopTimeout); err != nil {
+		if containerInfo.State == pausedState {
+			if pauseErr := u.containerOpsSvc.PauseContainer(ctx, containerInfo.ID); pauseErr != nil {
+				logger.Warn("重新暂停容器 %s 失败: %v", containerInfo.Name, pauseErr)
+			}
+		}
+		return "", "", fmt.Errorf("停止容器失败: %w", err)
+	}
+
+	// 备份旧容器，失败时放弃本次更新
+	if u.opts.BackupBeforeUpdate || containerInfo.Labels[backupLabel] == "true" {
+		if err := u.backupContainer(ctx, containerInfo); err != nil {
+			if shouldStart {
+				u.restartOldContainer(ctx, containerInfo)
+			}
+			return "", "", fmt.Errorf("备份容器失败: %w", err)
+		}
+	}
+
+	// 3. 重命名旧容器，释放原名称给新容器
+	oldName := backupContainerName(containerInfo.Name)
+	if err := u.containerOpsSvc.RenameContainer(ctx, containerInfo.ID, oldName); err != nil {
+		if shouldStart {
+			u.restartOldContainer(ctx, containerInfo)
+		}
+		return "", "", fmt.Errorf("重命名旧容器失败: %w", err)
+	}
+
+	// 4. 使用新镜像创建新容器
+	newContainerID, err := u.containerOpsSvc.RecreateContainer(ctx, containerConfig, imageInfo, newImage, containerInfo.Name, !u.opts.ResetEntrypoint)
 	if err != nil {
-		return "", err
+		u.restoreContainer(ctx, containerInfo, newContainerID, shouldStart)
+		return "", "", fmt.Errorf("创建新容器失败: %w", err)
 	}
 
-	// 连接其他网络
-	if !(hostConfig.NetworkMode.IsHost()) {
-		for k := range simpleNetworkConfig.EndpointsConfig {
-			err = u.containerOpsSvc.NetworkDisconnect(ctx, k, newContainerID, true)
-			if err != nil {
-				return "", err
+	// 5. 启动新容器（原容器未运行时保持停止状态）
+	if shouldStart {
+		if err := u.containerOpsSvc.StartContainer(ctx, newContainerID); err != nil {
+			u.restoreContainer(ctx, containerInfo, newContainerID, shouldStart)
+			return "", "", fmt.Errorf("启动新容器失败: %w", err)
+		}
+
+		// 等待新容器就绪，保证串行更新时依赖它的容器能正常启动
+		if u.opts.WaitReady > 0 {
+			if err := u.waitReady(ctx, newContainerID, u.opts.WaitReady); err != nil {
+				u.restoreContainer(ctx, containerInfo, newContainerID, shouldStart)
+				return "", "", fmt.Errorf("新容器未能就绪: %w", err)
 			}
 		}
 
-		for k, v := range networkingConfig.EndpointsConfig {
-			err = u.containerOpsSvc.NetworkConnect(ctx, k, newContainerID, v)
-			if err != nil {
-				return "", err
+		// 探测标签指定的 HTTP 健康检查地址，确认服务已恢复
+		if probeURL := containerInfo.Labels[healthcheckURLLabel]; probeURL != "" {
+			if err := probeHealthcheckURL(ctx, probeURL); err != nil {
+				u.restoreContainer(ctx, containerInfo, newContainerID, shouldStart)
+				return "", "", fmt.Errorf("健康检查地址 %s 探测失败: %w", probeURL, err)
+			}
+		}
+
+		// 原容器处于暂停状态时，新容器同样保持暂停
+		if containerInfo.State == pausedState {
+			if err := u.containerOpsSvc.PauseContainer(ctx, newContainerID); err != nil {
+				logger.Warn("重新暂停容器 %s 失败: %v", containerInfo.Name, err)
 			}
 		}
+	} else {
+		logger.Info("容器 %s 原状态为 %s，更新后保持停止", containerInfo.Name, containerInfo.State)
+	}
+
+	// 6. 新容器就绪后删除旧容器
+	if err := u.containerOpsSvc.RemoveContainer(ctx, containerInfo.ID, true); err != nil {
+		logger.Warn("删除旧容器 %s (%s) 失败，请手动清理: %v", oldName, containerInfo.ID, err)
 	}
 
-	return newContainerID, nil
+	logger.Info("容器 %s 已成功更新到新镜像 %s，新容器ID: %s", containerInfo.Name, newImage, utils.ShortID(newContainerID))
+	return imageInfo.ID, newContainerID, nil
 }
 
-// UpdateContainer 更新容器到新镜像
-func (u *Operator) updateContainer(ctx context.Context, containerInfo types.ContainerInfo, newImage string) error {
-	logger.Info("开始更新容器 %s (%s) 到新镜像 %s", containerInfo.Name, containerInfo.ID, newImage)
+// waitReady 等待容器进入运行状态，配置了健康检查时还需等待健康检查通过
+func (u *Operator) waitReady(ctx context.Context, containerID string, timeout time.Duration) error {
+	logger.Info("等待新容器 %s 就绪，最长 %v", utils.ShortID(containerID), timeout)
 
-	// 1. 获取容器完整配置
-	containerConfig, err := u.containerOpsSvc.GetContainerConfig(ctx, containerInfo.ID)
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for {
+		containerJSON, err := u.containerOpsSvc.GetContainerConfig(ctx, containerID)
+		if err != nil {
+			return err
+		}
+
+		state := containerJSON.State
+		if state != nil {
+			if state.Running && !state.Restarting {
+				if state.Health == nil || state.Health.Status == dockerTypes.Healthy {
+					logger.Info("新容器 %s 已就绪", utils.ShortID(containerID))
+					return nil
+				}
+				if state.Health.Status == dockerTypes.Unhealthy {
+					return fmt.Errorf("健康检查未通过")
+				}
+			} else if state.Status == "exited" || state.Status == "dead" {
+				return fmt.Errorf("容器已退出（退出码: %d）", state.ExitCode)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("等待超时（状态: %s）", containerStatus(state))
+		case <-ticker.C:
+		}
+	}
+}
+
+// probeHealthcheckURL 对健康检查地址发起 GET 请求，返回 2xx 视为成功，失败时按 probeInterval 重试
+func probeHealthcheckURL(ctx context.Context, probeURL string) error {
+	logger.Info("探测健康检查地址 %s", probeURL)
+
+	client := &http.Client{Timeout: probeTimeout}
+	var lastErr error
+	for attempt := 1; attempt <= probeAttempts; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, probeURL, nil)
+		if err != nil {
+			return err
+		}
+
+		resp, err := client.Do(req)
+		if err == nil {
+			resp.Body.Close()
+			if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+				logger.Info("健康检查地址 %s 探测成功", probeURL)
+				return nil
+			}
+			err = fmt.Errorf("状态码 %d", resp.StatusCode)
+		}
+		lastErr = err
+		logger.Debug("第 %d 次探测健康检查地址 %s 失败: %v", attempt, probeURL, err)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(probeInterval):
+		}
+	}
+
+	return fmt.Errorf("%d 次探测均失败: %w", probeAttempts, lastErr)
+}
+
+// backupContainer 将容器提交为备份镜像并清理过期备份
+func (u *Operator) backupContainer(ctx context.Context, containerInfo types.ContainerInfo) error {
+	ref, err := u.imageSvc.CommitBackup(ctx, containerInfo.ID, containerInfo.Name)
 	if err != nil {
-		return fmt.Errorf("获取容器配置失败: %w", err)
+		return err
 	}
+	logger.Info("容器 %s 已备份为镜像 %s", containerInfo.Name, ref)
 
-	// 获取新镜像信息
-	imageInfo, err := u.containerOpsSvc.GetImageInspect(ctx, newImage)
+	if u.opts.BackupKeep > 0 {
+		if err := u.imageSvc.PruneBackups(ctx, containerInfo.Name, u.opts.BackupKeep); err != nil {
+			logger.Warn("清理容器 %s 的过期备份失败: %v", containerInfo.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// restoreContainer 更新失败时删除新容器并恢复旧容器的名称和运行状态
+func (u *Operator) restoreContainer(ctx context.Context, containerInfo types.ContainerInfo, newContainerID string, shouldStart bool) {
+	// 更新可能因取消而失败，恢复旧容器不能随之中止
+	ctx = context.WithoutCancel(ctx)
+	logger.Warn("容器 %s 更新失败，开始恢复旧容器", containerInfo.Name)
+
+	if newContainerID != "" {
+		if err := u.containerOpsSvc.RemoveContainer(ctx, newContainerID, true); err != nil {
+			logger.Error("删除新容器 %s 失败: %v", utils.ShortID(newContainerID), err)
+		}
+	}
+
+	if err := u.containerOpsSvc.RenameContainer(ctx, containerInfo.ID, containerInfo.Name); err != nil {
+		logger.Error("恢复旧容器 %s 名称失败: %v", containerInfo.Name, err)
+	}
+
+	if shouldStart && !u.restartOldContainer(ctx, containerInfo) {
+		return
+	}
+
+	logger.Info("旧容器 %s 已恢复", containerInfo.Name)
+}
+
+// restartOldContainer 重新启动旧容器，原本处于暂停状态的容器启动后重新暂停，启动失败时返回 false
+func (u *Operator) restartOldContainer(ctx context.Context, containerInfo types.ContainerInfo) bool {
+	ctx = context.WithoutCancel(ctx)
+	if err := u.containerOpsSvc.StartContainer(ctx, containerInfo.ID); err != nil {
+		logger.Error("重新启动旧容器 %s 失败: %v", containerInfo.Name, err)
+		return false
+	}
+
+	if containerInfo.State == pausedState {
+		if err := u.containerOpsSvc.PauseContainer(ctx, containerInfo.ID); err != nil {
+			logger.Warn("重新暂停容器 %s 失败: %v", containerInfo.Name, err)
+		}
+	}
+	return true
+}
+
+// backupContainerName 生成更新期间旧容器的临时名称
+func backupContainerName(name string) string {
+	return fmt.Sprintf("%s_watchducker_%d", name, time.Now().Unix())
+}
+
+// orphanNamePattern 匹配 backupContainerName 生成的旧容器名称，子匹配为原容器名称
+var orphanNamePattern = regexp.MustCompile(`^(.+)_watchducker_\d+$`)
+
+// CleanupOrphans 清理更新中断时遗留的已停止旧容器，返回清理的数量。
+// 仅当同名的新容器存在时才删除，避免误删恢复失败后唯一保留的旧容器
+func (u *Operator) CleanupOrphans(ctx context.Context) (int, error) {
+	containers, err := u.containerOpsSvc.GetAll(ctx, true)
 	if err != nil {
-		return fmt.Errorf("获取镜像信息失败: %w", err)
+		return 0, fmt.Errorf("获取所有容器失败: %w", err)
 	}
 
-	// 2. 停止容器
-	stopTimeout := 30 * time.Second
-	if err := u.containerOpsSvc.StopContainer(ctx, containerInfo.ID, &stopTimeout); err != nil {
-		return fmt.Errorf("停止容器失败: %w", err)
+	names := make(map[string]struct{}, len(containers))
+	for _, container := range containers {
+		names[container.Name] = struct{}{}
 	}
 
-	// 3. 删除容器
-	if err := u.containerOpsSvc.RemoveContainer(ctx, containerInfo.ID, true); err != nil {
-		return fmt.Errorf("删除容器失败: %w", err)
+	removed := 0
+	for _, container := range containers {
+		match := orphanNamePattern.FindStringSubmatch(container.Name)
+		if match == nil || !isStoppedState(container.State) {
+			continue
+		}
+
+		if _, ok := names[match[1]]; !ok {
+			logger.Warn("遗留旧容器 %s 对应的容器 %s 不存在，保留以便手动恢复", container.Name, match[1])
+			continue
+		}
+
+		if err := u.containerOpsSvc.RemoveContainer(ctx, container.ID, true); err != nil {
+			logger.Warn("清理遗留旧容器 %s 失败: %v", container.Name, err)
+			continue
+		}
+		logger.Info("已清理遗留旧容器 %s", container.Name)
+		removed++
 	}
 
-	// 4. 使用新镜像创建新容器
-	newContainerID, err := u.createNewContainer(ctx, containerConfig, imageInfo, newImage, containerInfo.Name)
+	return removed, nil
+}
+
+// ApplyResourceLabels 按容器的 watchducker.cpus/watchducker.memory 标签热更新资源限制，
+// 仅在标签与当前 HostConfig 不一致时调用 docker update，返回更新的容器数量
+func (u *Operator) ApplyResourceLabels(ctx context.Context, containers []types.ContainerInfo) (int, error) {
+	var errs []error
+	updated := 0
+
+	for _, containerInfo := range containers {
+		desired, err := resourcesFromLabels(containerInfo.Labels)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("容器 %s 的资源限制标签无效: %w", containerInfo.Name, err))
+			continue
+		}
+		if desired == nil {
+			continue
+		}
+
+		containerJSON, err := u.containerOpsSvc.GetContainerConfig(ctx, containerInfo.ID)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("获取容器 %s 配置失败: %w", containerInfo.Name, err))
+			continue
+		}
+
+		current := containerJSON.HostConfig.Resources
+		changes := container.Resources{}
+		if desired.NanoCPUs != 0 && desired.NanoCPUs != current.NanoCPUs {
+			changes.NanoCPUs = desired.NanoCPUs
+		}
+		if desired.Memory != 0 && desired.Memory != current.Memory {
+			changes.Memory = desired.Memory
+		}
+		if changes.NanoCPUs == 0 && changes.Memory == 0 {
+			continue
+		}
+
+		if err := u.containerOpsSvc.UpdateContainerResources(ctx, containerInfo.ID, changes); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		logger.Info("容器 %s 的资源限制已按标签更新", containerInfo.Name)
+		updated++
+	}
+
+	return updated, errors.Join(errs...)
+}
+
+// resourcesFromLabels 解析容器标签中期望的资源限制，未设置任何资源标签时返回 nil
+func resourcesFromLabels(labels map[string]string) (*container.Resources, error) {
+	cpus, hasCPUs := labels[cpusLabel]
+	memory, hasMemory := labels[memoryLabel]
+	if !hasCPUs && !hasMemory {
+		return nil, nil
+	}
+
+	resources := &container.Resources{}
+	if hasCPUs {
+		value, err := strconv.ParseFloat(cpus, 64)
+		if err != nil || value <= 0 {
+			return nil, fmt.Errorf("%s=%q 不是有效的 CPU 数", cpusLabel, cpus)
+		}
+		resources.NanoCPUs = int64(value * 1e9)
+	}
+	if hasMemory {
+		value, err := units.RAMInBytes(memory)
+		if err != nil || value <= 0 {
+			return nil, fmt.Errorf("%s=%q 不是有效的内存大小", memoryLabel, memory)
+		}
+		resources.Memory = value
+	}
+	return resources, nil
+}
+
+// pausedState 容器被 docker pause 暂停时的状态
+const pausedState = "paused"
+
+// isStoppedState 判断容器状态是否为未运行
+func isStoppedState(state string) bool {
+	switch state {
+	case "created", "exited", "dead":
+		return true
+	}
+	return false
+}
+
+// unhealthyStatus 读取容器当前的运行和健康状态并写入 containerInfo.Health，
+// 容器正在重启或健康检查未通过时返回对应状态（restarting/unhealthy/starting），否则返回空字符串
+func (u *Operator) unhealthyStatus(ctx context.Context, containerInfo *types.ContainerInfo) (string, error) {
+	containerJSON, err := u.containerOpsSvc.GetContainerConfig(ctx, containerInfo.ID)
 	if err != nil {
-		return fmt.Errorf("创建新容器失败: %w", err)
+		return "", err
 	}
 
-	// 5. 启动新容器
-	if err := u.containerOpsSvc.StartContainer(ctx, newContainerID); err != nil {
-		return fmt.Errorf("启动新容器失败: %w", err)
+	state := containerJSON.State
+	if state == nil {
+		return "", nil
 	}
+	if state.Restarting {
+		return "restarting", nil
+	}
+	if state.Health != nil {
+		containerInfo.Health = state.Health.Status
+		if state.Health.Status != "healthy" {
+			return state.Health.Status, nil
+		}
+	}
+	return "", nil
+}
 
-	logger.Info("容器 %s 已成功更新到新镜像 %s，新容器ID: %s", containerInfo.Name, newImage, newContainerID[:12])
-	return nil
+// unhealthyDetail 生成跳过更新时的日志说明
+func unhealthyDetail(status string, err error) string {
+	if err != nil {
+		return err.Error()
+	}
+	return status
 }
 
-// UpdateContainersWithNewImages 批量更新容器到新镜像
-func (u *Operator) updateContainers(ctx context.Context, containers []types.ContainerInfo, imageUpdates map[string]string) error {
+// UpdateContainersWithNewImages 批量更新容器到新镜像，按 Concurrency 限制同时重建的容器数量，返回每个容器的更新结果
+func (u *Operator) updateContainers(ctx context.Context, containers []types.ContainerInfo, imageUpdates map[string]string) ([]types.ContainerUpdateResult, error) {
 	logger.Info("开始批量更新 %d 个容器", len(containers))
 
-	var errors []error
+	concurrency := u.opts.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		errs    []error
+		results []types.ContainerUpdateResult
+		updated int
+	)
+	sem := make(chan struct{}, concurrency)
 
 	for _, containerInfo := range containers {
 		newImage, exists := imageUpdates[containerInfo.Image]
@@ -138,21 +497,90 @@ func (u *Operator) updateContainers(ctx context.Context, containers []types.Cont
 			continue
 		}
 
-		if err := u.updateContainer(ctx, containerInfo, newImage); err != nil {
-			logger.Error("更新容器 %s 失败: %v", containerInfo.Name, err)
-			errors = append(errors, fmt.Errorf("更新容器 %s 失败: %w", containerInfo.Name, err))
+		// 已取消（收到退出信号或运行超时）时不再开始新的容器更新
+		if ctx.Err() != nil {
+			logger.Warn("更新已取消，跳过剩余容器")
+			mu.Lock()
+			errs = append(errs, fmt.Errorf("更新已取消: %w", ctx.Err()))
+			mu.Unlock()
+			break
 		}
+
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(containerInfo types.ContainerInfo, newImage string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			result := types.ContainerUpdateResult{
+				Name:        containerInfo.Name,
+				ContainerID: containerInfo.ID,
+				Image:       newImage,
+				OldImageID:  containerInfo.ImageID,
+			}
+			defer func() {
+				mu.Lock()
+				results = append(results, result)
+				mu.Unlock()
+			}()
+
+			// 单个容器更新 panic 时记为该容器更新失败，不影响其他容器和守护进程
+			defer func() {
+				if r := recover(); r != nil {
+					logger.Error("更新容器 %s 时发生 panic: %v\n%s", containerInfo.Name, r, debug.Stack())
+					result.Error = fmt.Sprintf("panic: %v", r)
+					mu.Lock()
+					errs = append(errs, fmt.Errorf("更新容器 %s 失败: panic: %v", containerInfo.Name, r))
+					mu.Unlock()
+				}
+			}()
+
+			if u.opts.SkipUnhealthy {
+				if status, err := u.unhealthyStatus(ctx, &containerInfo); err != nil || status != "" {
+					detail := unhealthyDetail(status, err)
+					logger.Warn("容器 %s 当前未稳定运行（%s），跳过更新", containerInfo.Name, detail)
+					result.Skipped = true
+					result.Error = detail
+					return
+				}
+			}
+
+			newImageID, newContainerID, err := u.updateContainer(ctx, containerInfo, newImage)
+			if err != nil {
+				logger.Error("更新容器 %s 失败: %v", containerInfo.Name, err)
+				result.Error = err.Error()
+				mu.Lock()
+				errs = append(errs, fmt.Errorf("更新容器 %s 失败: %w", containerInfo.Name, err))
+				mu.Unlock()
+				return
+			}
+			result.NewImageID = newImageID
+			result.NewContainerID = newContainerID
+			result.Success = true
+			if u.cleanupEnabled(containerInfo) && containerInfo.ImageID != newImageID {
+				u.removeOldImage(ctx, containerInfo)
+			}
+			mu.Lock()
+			updated++
+			mu.Unlock()
+		}(containerInfo, newImage)
 	}
+	wg.Wait()
 
-	if len(errors) > 0 {
-		return fmt.Errorf("批量更新过程中出现 %d 个错误: %v", len(errors), errors)
+	// 并发完成的顺序不固定，按容器名称排序便于展示
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Name < results[j].Name
+	})
+
+	if len(errs) > 0 {
+		return results, fmt.Errorf("批量更新过程中出现 %d 个错误: %v", len(errs), errs)
 	}
 
-	logger.Info("批量更新完成，成功更新 %d 个容器", len(containers))
-	return nil
+	logger.Info("批量更新完成，成功更新 %d 个容器", updated)
+	return results, nil
 }
 
-// UpdateContainers 更新有镜像更新的容器
+// UpdateContainers 更新有镜像更新的容器，每个容器的更新结果写入 result.Updates
 func (c *Operator) UpdateContainersByBatchCheckResult(ctx context.Context, result *types.BatchCheckResult) error {
 	if result.Summary.Updated == 0 {
 		logger.Info("没有需要更新的容器")
@@ -161,20 +589,41 @@ func (c *Operator) UpdateContainersByBatchCheckResult(ctx context.Context, resul
 
 	logger.Info("发现 %d 个容器需要更新，开始自动更新流程", result.Summary.Updated)
 
-	// 构建镜像更新映射
+	// 构建镜像更新映射，需要切换到目标镜像的容器单独记录
 	imageUpdates := make(map[string]string)
+	switching := make(map[string]struct{})
 	for _, imageResult := range result.Images {
-		if imageResult.IsUpdated && imageResult.Error == "" {
-			imageUpdates[imageResult.Name] = imageResult.Name // 使用相同的镜像名称，但实际是新版本
+		if imageResult.Error != "" {
+			continue
+		}
+		if imageResult.IsUpdated {
+			// 默认使用相同的镜像名称（实际是新版本），有更高版本 tag 时使用新 tag
+			imageUpdates[imageResult.Name] = imageResult.Name
+			if imageResult.UpdateRef != "" {
+				imageUpdates[imageResult.Name] = imageResult.UpdateRef
+			}
+		}
+		for _, id := range imageResult.SwitchContainers {
+			switching[id] = struct{}{}
 		}
 	}
 
-	// 更新所有使用这些镜像的容器
+	// 更新所有使用有更新镜像的容器，以及需要切换到目标镜像的容器
 	var containersToUpdate []types.ContainerInfo
 	for _, container := range result.Containers {
-		if _, exists := imageUpdates[container.Image]; exists {
-			containersToUpdate = append(containersToUpdate, container)
+		_, updated := imageUpdates[container.Image]
+		_, switched := switching[container.ID]
+		if !updated && !switched {
+			continue
+		}
+
+		// 常规更新流程会先停止旧容器，自身容器停止后进程即退出，只能通过 SelfUpdater 更新
+		if isSelf, _ := isSelfContainer(container, container.Image); isSelf {
+			logger.Warn("跳过自身容器 %s，自身仅通过自我更新流程更新", container.Name)
+			continue
 		}
+
+		containersToUpdate = append(containersToUpdate, container)
 	}
 
 	if len(containersToUpdate) == 0 {
@@ -182,12 +631,59 @@ func (c *Operator) UpdateContainersByBatchCheckResult(ctx context.Context, resul
 		return nil
 	}
 
+	// 切换镜像的容器直接使用已是最新的目标镜像重建
+	for _, container := range containersToUpdate {
+		if _, exists := imageUpdates[container.Image]; !exists {
+			imageUpdates[container.Image] = container.Image
+		}
+	}
+
 	// 执行批量更新
-	if err := c.updateContainers(ctx, containersToUpdate, imageUpdates); err != nil {
-		return err
+	updates, err := c.updateContainers(ctx, containersToUpdate, imageUpdates)
+	result.Updates = updates
+	return err
+}
+
+// cleanupEnabled 判断容器更新后是否清理旧镜像，watchducker.cleanup 标签优先于全局的 --clean
+func (u *Operator) cleanupEnabled(containerInfo types.ContainerInfo) bool {
+	switch containerInfo.Labels[cleanupLabel] {
+	case "true":
+		return true
+	case "false":
+		return false
+	}
+	return u.opts.CleanUp
+}
+
+// removeOldImage 删除容器更新前使用的旧镜像，旧镜像仍被其他容器使用或带有其他标签时保留
+func (u *Operator) removeOldImage(ctx context.Context, containerInfo types.ContainerInfo) {
+	if containerInfo.ImageID == "" {
+		return
 	}
 
-	return nil
+	if err := u.imageSvc.RemoveImage(ctx, containerInfo.ImageID); err != nil {
+		logger.Debug("保留容器 %s 的旧镜像 %s: %v", containerInfo.Name, utils.ShortID(strings.TrimPrefix(containerInfo.ImageID, "sha256:")), err)
+		return
+	}
+	logger.Info("已清理容器 %s 的旧镜像 %s", containerInfo.Name, utils.ShortID(strings.TrimPrefix(containerInfo.ImageID, "sha256:")))
+}
+
+// KeepsOldImages 判断是否有成功更新的容器通过 watchducker.cleanup=false 要求保留旧镜像，
+// 此时不能执行全局的悬空镜像清理
+func KeepsOldImages(result *types.BatchCheckResult) bool {
+	updated := make(map[string]struct{}, len(result.Updates))
+	for _, update := range result.Updates {
+		if update.Success {
+			updated[update.Name] = struct{}{}
+		}
+	}
+
+	for _, container := range result.Containers {
+		if _, ok := updated[container.Name]; ok && container.Labels[cleanupLabel] == "false" {
+			return true
+		}
+	}
+	return false
 }
 
 // CleanDanglingImages 清理悬空镜像
@@ -0,0 +1,176 @@
+package core
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+
+	"watchducker/internal/docker/dockertest"
+	"watchducker/internal/types"
+
+	dockerTypes "github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/network"
+)
+
+func TestIsStoppedState(t *testing.T) {
+	tests := map[string]bool{
+		"created":    true,
+		"exited":     true,
+		"dead":       true,
+		"running":    false,
+		"paused":     false,
+		"restarting": false,
+	}
+	for state, want := range tests {
+		if got := isStoppedState(state); got != want {
+			t.Errorf("isStoppedState(%q) = %v, want %v", state, got, want)
+		}
+	}
+}
+
+// newUpdateServer 模拟更新单个容器所需的 Docker API，记录 start/pause/unpause 等调用的容器ID
+func newUpdateServer(t *testing.T) (string, func() map[string][]string) {
+	const newContainerID = "new0123456789abcdef"
+
+	var mu sync.Mutex
+	calls := make(map[string][]string)
+	record := func(action string) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			calls[action] = append(calls[action], r.PathValue("id"))
+			mu.Unlock()
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}
+
+	host := dockertest.NewServer(t, map[string]http.HandlerFunc{
+		"GET /containers/{id}/json": func(w http.ResponseWriter, r *http.Request) {
+			dockertest.WriteJSON(w, http.StatusOK, dockerTypes.ContainerJSON{
+				ContainerJSONBase: &dockerTypes.ContainerJSONBase{
+					ID:         r.PathValue("id"),
+					Name:       "/web",
+					Image:      "sha256:old",
+					HostConfig: &container.HostConfig{},
+				},
+				Config:          &container.Config{Image: "nginx:latest"},
+				NetworkSettings: &dockerTypes.NetworkSettings{Networks: map[string]*network.EndpointSettings{}},
+			})
+		},
+		"GET /images/{name}/json": func(w http.ResponseWriter, r *http.Request) {
+			dockertest.WriteJSON(w, http.StatusOK, dockerTypes.ImageInspect{ID: "sha256:new", Config: &container.Config{}})
+		},
+		"POST /containers/create": func(w http.ResponseWriter, r *http.Request) {
+			dockertest.WriteJSON(w, http.StatusCreated, container.CreateResponse{ID: newContainerID})
+		},
+		"POST /containers/{id}/stop":    record("stop"),
+		"POST /containers/{id}/rename":  record("rename"),
+		"POST /containers/{id}/start":   record("start"),
+		"POST /containers/{id}/pause":   record("pause"),
+		"POST /containers/{id}/unpause": record("unpause"),
+		"DELETE /containers/{id}":       record("remove"),
+	})
+
+	return host, func() map[string][]string {
+		mu.Lock()
+		defer mu.Unlock()
+		return calls
+	}
+}
+
+func TestUpdateContainerKeepsState(t *testing.T) {
+	const oldID = "old0123456789"
+	const newID = "new0123456789abcdef"
+
+	tests := []struct {
+		state       string
+		wantStart   []string
+		wantPause   []string
+		wantUnpause []string
+	}{
+		{state: "exited"},
+		{state: "created"},
+		{state: "running", wantStart: []string{newID}},
+		{state: "paused", wantStart: []string{newID}, wantPause: []string{newID}, wantUnpause: []string{oldID}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.state, func(t *testing.T) {
+			host, calls := newUpdateServer(t)
+			operator, err := NewOperator(host, OperatorOptions{})
+			if err != nil {
+				t.Fatalf("创建更新器失败: %v", err)
+			}
+			defer operator.Close()
+
+			info := types.ContainerInfo{ID: oldID, Name: "web", Image: "nginx:latest", ImageID: "sha256:old", State: tt.state}
+			_, newContainerID, err := operator.updateContainer(context.Background(), info, "nginx:latest")
+			if err != nil {
+				t.Fatalf("updateContainer 返回错误: %v", err)
+			}
+			if newContainerID != newID {
+				t.Errorf("新容器ID = %q, want %q", newContainerID, newID)
+			}
+
+			got := calls()
+			assertCalls(t, "start", got["start"], tt.wantStart)
+			assertCalls(t, "pause", got["pause"], tt.wantPause)
+			assertCalls(t, "unpause", got["unpause"], tt.wantUnpause)
+			assertCalls(t, "remove", got["remove"], []string{oldID})
+		})
+	}
+}
+
+// assertCalls 比较某类 API 调用涉及的容器ID
+func assertCalls(t *testing.T, action string, got, want []string) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Errorf("%s 调用 = %v, want %v", action, got, want)
+		return
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			t.Errorf("%s 调用 = %v, want %v", action, got, want)
+			return
+		}
+	}
+}
+
+func TestUpdateOnlySwitchingContainers(t *testing.T) {
+	const (
+		switchID = "switch0123456789abcd"
+		plainID  = "plain0123456789abcde"
+		doneID   = "done0123456789abcdef"
+	)
+
+	host, calls := newUpdateServer(t)
+	operator, err := NewOperator(host, OperatorOptions{})
+	if err != nil {
+		t.Fatalf("创建更新器失败: %v", err)
+	}
+	defer operator.Close()
+
+	// 目标镜像本身无更新，只有带标签且仍在使用旧镜像的容器需要切换
+	target := map[string]string{targetImageLabel: "nginx:latest"}
+	result := &types.BatchCheckResult{
+		Containers: []types.ContainerInfo{
+			{ID: plainID, Name: "plain", Image: "nginx:latest", ImageID: "sha256:new", State: "running"},
+			{ID: switchID, Name: "switch", Image: "nginx:latest", ImageID: "sha256:old", State: "running", Labels: target},
+			{ID: doneID, Name: "done", Image: "nginx:latest", ImageID: "sha256:new", State: "running", Labels: target},
+		},
+		Images: []*types.ImageCheckResult{{Name: "nginx:latest", SwitchContainers: []string{switchID}}},
+	}
+	result.Summary.Updated = 1
+
+	if err := operator.UpdateContainersByBatchCheckResult(context.Background(), result); err != nil {
+		t.Fatalf("UpdateContainersByBatchCheckResult 返回错误: %v", err)
+	}
+
+	got := calls()
+	assertCalls(t, "stop", got["stop"], []string{switchID})
+	assertCalls(t, "remove", got["remove"], []string{switchID})
+	if len(result.Updates) != 1 || result.Updates[0].Name != "switch" || !result.Updates[0].Success {
+		t.Errorf("更新结果 = %+v, want 只有 switch 更新成功", result.Updates)
+	}
+}
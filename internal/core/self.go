@@ -0,0 +1,195 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"watchducker/internal/types"
+	"watchducker/pkg/logger"
+	"watchducker/pkg/utils"
+
+	dockerTypes "github.com/docker/docker/api/types"
+)
+
+// SelfUpdater watchducker 自我更新器
+// 自身容器不能先停止再重建，因此采用“重命名旧容器 → 创建并启动新容器 → 确认稳定 → 删除旧容器”的流程
+type SelfUpdater struct {
+	operator *Operator
+	grace    time.Duration
+}
+
+// NewSelfUpdater 创建自我更新器，grace 为删除旧容器前等待新容器稳定运行的时间
+func NewSelfUpdater(dockerHost string, grace time.Duration) (*SelfUpdater, error) {
+	operator, err := NewOperator(dockerHost, OperatorOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	return &SelfUpdater{
+		operator: operator,
+		grace:    grace,
+	}, nil
+}
+
+// SelfUpdate 检查并更新 watchducker 自身容器，返回自身镜像的检查结果
+// 更新成功时旧容器会被删除、当前进程随之退出，因此 onUpdated 在删除旧容器前调用
+func (su *SelfUpdater) SelfUpdate(ctx context.Context, onUpdated func(*types.ImageCheckResult)) (*types.ImageCheckResult, error) {
+	self, err := su.findSelfContainer(ctx)
+	if err != nil {
+		return nil, err
+	}
+	logger.Info("找到自身容器: %s (%s)", self.Name, self.ID)
+
+	imageName, err := su.operator.imageSvc.NormalizeReference(ctx, self.Image)
+	if err != nil {
+		return nil, fmt.Errorf("解析自身镜像失败: %w", err)
+	}
+
+	result, err := su.operator.imageSvc.CheckUpdate(ctx, imageName)
+	if err != nil {
+		return result, fmt.Errorf("检查自身镜像更新失败: %w", err)
+	}
+	if !result.IsUpdated {
+		logger.Info("自身镜像 %s 已是最新", imageName)
+		return result, nil
+	}
+
+	if err := su.replace(ctx, self, imageName, func() {
+		if onUpdated != nil {
+			onUpdated(result)
+		}
+	}); err != nil {
+		result.Error = err.Error()
+		return result, err
+	}
+
+	return result, nil
+}
+
+// findSelfContainer 查找当前运行的 watchducker 容器，优先匹配主机名（默认即容器短ID），其次匹配自身标签
+func (su *SelfUpdater) findSelfContainer(ctx context.Context) (types.ContainerInfo, error) {
+	containers, err := su.operator.containerSvc.GetAll(ctx, false)
+	if err != nil {
+		return types.ContainerInfo{}, fmt.Errorf("获取容器列表失败: %w", err)
+	}
+
+	if hostname, err := os.Hostname(); err == nil {
+		for _, container := range containers {
+			if strings.HasPrefix(container.ID, hostname) || strings.HasPrefix(hostname, container.ID) {
+				return container, nil
+			}
+		}
+	}
+
+	var labeled []types.ContainerInfo
+	for _, container := range containers {
+		if container.Labels[selfLabel] == "true" {
+			labeled = append(labeled, container)
+		}
+	}
+	if len(labeled) == 1 {
+		return labeled[0], nil
+	}
+
+	return types.ContainerInfo{}, fmt.Errorf("未找到 watchducker 自身容器（当前可能未在容器中运行）")
+}
+
+// replace 使用新镜像替换自身容器，新容器未能稳定运行时恢复旧容器
+func (su *SelfUpdater) replace(ctx context.Context, self types.ContainerInfo, newImage string, beforeRemove func()) error {
+	ops := su.operator.containerOpsSvc
+
+	containerConfig, err := ops.GetContainerConfig(ctx, self.ID)
+	if err != nil {
+		return fmt.Errorf("获取自身容器配置失败: %w", err)
+	}
+
+	imageInfo, err := ops.GetImageInspect(ctx, newImage)
+	if err != nil {
+		return fmt.Errorf("获取镜像信息失败: %w", err)
+	}
+
+	// 1. 重命名旧容器，释放原名称
+	oldName := backupContainerName(self.Name)
+	if err := ops.RenameContainer(ctx, self.ID, oldName); err != nil {
+		return fmt.Errorf("重命名自身容器失败: %w", err)
+	}
+
+	// 2. 创建并启动新容器
+	newContainerID, err := su.operator.containerOpsSvc.RecreateContainer(ctx, containerConfig, imageInfo, newImage, self.Name, true)
+	if err == nil {
+		err = ops.StartContainer(ctx, newContainerID)
+	}
+
+	// 3. 等待新容器稳定运行
+	if err == nil {
+		err = su.waitStable(ctx, newContainerID)
+	}
+
+	if err != nil {
+		su.rollback(ctx, self, newContainerID)
+		return fmt.Errorf("自我更新失败，已恢复旧容器: %w", err)
+	}
+
+	// 4. 删除旧容器，当前进程会随之退出
+	beforeRemove()
+	logger.Info("新的 watchducker 容器 %s 已稳定运行，删除旧容器 %s", utils.ShortID(newContainerID), oldName)
+	if err := ops.RemoveContainer(ctx, self.ID, true); err != nil {
+		return fmt.Errorf("删除旧的自身容器失败: %w", err)
+	}
+
+	return nil
+}
+
+// waitStable 等待 grace 时间后确认新容器仍在运行
+func (su *SelfUpdater) waitStable(ctx context.Context, containerID string) error {
+	logger.Info("等待新容器 %s 稳定运行 %v", utils.ShortID(containerID), su.grace)
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(su.grace):
+	}
+
+	containerJSON, err := su.operator.containerOpsSvc.GetContainerConfig(ctx, containerID)
+	if err != nil {
+		return err
+	}
+
+	state := containerJSON.State
+	if state == nil || !state.Running || state.Restarting {
+		return fmt.Errorf("新容器 %s 未能稳定运行（状态: %s）", utils.ShortID(containerID), containerStatus(state))
+	}
+
+	return nil
+}
+
+// rollback 删除新容器并恢复旧容器名称，旧容器始终保持运行
+func (su *SelfUpdater) rollback(ctx context.Context, self types.ContainerInfo, newContainerID string) {
+	ops := su.operator.containerOpsSvc
+
+	if newContainerID != "" {
+		if err := ops.RemoveContainer(ctx, newContainerID, true); err != nil {
+			logger.Error("删除新容器 %s 失败: %v", utils.ShortID(newContainerID), err)
+		}
+	}
+
+	if err := ops.RenameContainer(ctx, self.ID, self.Name); err != nil {
+		logger.Error("恢复自身容器名称 %s 失败: %v", self.Name, err)
+	}
+}
+
+// containerStatus 返回容器状态描述
+func containerStatus(state *dockerTypes.ContainerState) string {
+	if state == nil {
+		return "unknown"
+	}
+	return state.Status
+}
+
+// Close 关闭所有资源
+func (su *SelfUpdater) Close() error {
+	return su.operator.Close()
+}
@@ -0,0 +1,83 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"watchducker/internal/docker"
+	"watchducker/pkg/logger"
+
+	"github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/api/types/filters"
+)
+
+// watchRetryDelay 事件流中断后重新订阅前的等待时间
+const watchRetryDelay = 10 * time.Second
+
+// Watcher Docker 容器事件监听器
+type Watcher struct {
+	clientManager *docker.ClientManager
+}
+
+// NewWatcher 创建事件监听器，dockerHost 为空时使用环境变量中的 Docker 地址
+func NewWatcher(dockerHost string) (*Watcher, error) {
+	clientManager, err := docker.NewClientManager(dockerHost)
+	if err != nil {
+		return nil, fmt.Errorf("创建 Docker 客户端管理器失败: %w", err)
+	}
+
+	return &Watcher{clientManager: clientManager}, nil
+}
+
+// Watch 订阅容器的 create/start 事件并以容器ID、名称和标签调用 handler，
+// 事件流中断时等待后重新订阅，直到 ctx 取消
+func (w *Watcher) Watch(ctx context.Context, handler func(id, name string, labels map[string]string)) {
+	for {
+		err := w.watchOnce(ctx, handler)
+		if ctx.Err() != nil {
+			return
+		}
+		logger.Warn("Docker 事件流中断，%v 后重新订阅: %v", watchRetryDelay, err)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(watchRetryDelay):
+		}
+
+		// daemon 重启后旧连接不可用，等待其恢复并重建客户端
+		if err := w.clientManager.WaitReady(ctx); err != nil && ctx.Err() == nil {
+			logger.Warn("Docker 服务仍不可用，稍后重试: %v", err)
+		}
+	}
+}
+
+// watchOnce 订阅一次事件流，直到出错
+func (w *Watcher) watchOnce(ctx context.Context, handler func(id, name string, labels map[string]string)) error {
+	cli := w.clientManager.GetClient()
+
+	messages, errs := cli.Events(ctx, events.ListOptions{
+		Filters: filters.NewArgs(
+			filters.Arg("type", string(events.ContainerEventType)),
+			filters.Arg("event", string(events.ActionCreate)),
+			filters.Arg("event", string(events.ActionStart)),
+		),
+	})
+
+	for {
+		select {
+		case msg := <-messages:
+			name := msg.Actor.Attributes["name"]
+			logger.Debug("收到容器事件: %s %s", msg.Action, name)
+			handler(msg.Actor.ID, name, msg.Actor.Attributes)
+		case err := <-errs:
+			return err
+		}
+	}
+}
+
+// Close 关闭所有资源
+func (w *Watcher) Close() error {
+	return w.clientManager.Close()
+}
@@ -3,35 +3,68 @@ package docker
 import (
 	"context"
 	"fmt"
+	"sync"
+	"time"
+
+	"watchducker/pkg/logger"
 
 	"github.com/docker/docker/client"
 )
 
+const (
+	// reconnectMaxWait 等待 Docker 服务恢复的最长时间，超过后本次操作失败，由下一次运行重试
+	reconnectMaxWait = time.Minute
+	// reconnectMaxBackoff 连续重连失败时两次尝试之间的最长间隔
+	reconnectMaxBackoff = 16 * time.Second
+)
+
 // ClientManager 统一的 Docker 客户端管理器
 type ClientManager struct {
+	host string
+
+	mu  sync.RWMutex
 	cli *client.Client
 }
 
 // NewClientManager 创建新的 Docker 客户端管理器
-func NewClientManager() (*ClientManager, error) {
-	cli, err := client.NewClientWithOpts(
+// host 为空时使用 DOCKER_HOST 等环境变量，TLS 配置始终读取 DOCKER_TLS_VERIFY、DOCKER_CERT_PATH
+func NewClientManager(host string) (*ClientManager, error) {
+	cli, err := newClient(host)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ClientManager{host: host, cli: cli}, nil
+}
+
+// newClient 按 host 创建 Docker 客户端
+func newClient(host string) (*client.Client, error) {
+	opts := []client.Opt{
 		client.FromEnv,
 		client.WithAPIVersionNegotiation(),
-	)
+	}
+	if host != "" {
+		opts = append(opts, client.WithHost(host))
+	}
+
+	cli, err := client.NewClientWithOpts(opts...)
 	if err != nil {
 		return nil, fmt.Errorf("创建 Docker 客户端失败: %w", err)
 	}
-
-	return &ClientManager{cli: cli}, nil
+	return cli, nil
 }
 
 // GetClient 获取 Docker 客户端实例
 func (cm *ClientManager) GetClient() *client.Client {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
 	return cm.cli
 }
 
 // Close 关闭 Docker 客户端连接
 func (cm *ClientManager) Close() error {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
 	if cm.cli != nil {
 		return cm.cli.Close()
 	}
@@ -40,9 +73,59 @@ func (cm *ClientManager) Close() error {
 
 // Ping 检查 Docker 服务是否可用
 func (cm *ClientManager) Ping(ctx context.Context) error {
-	_, err := cm.cli.Ping(ctx)
+	_, err := cm.GetClient().Ping(ctx)
 	if err != nil {
 		return fmt.Errorf("发现 Docker 服务不可用: %w", err)
 	}
 	return nil
 }
+
+// Reconnect 重建 Docker 客户端，丢弃 daemon 重启后已失效的连接和协商过的 API 版本
+func (cm *ClientManager) Reconnect() error {
+	cli, err := newClient(cm.host)
+	if err != nil {
+		return err
+	}
+
+	cm.mu.Lock()
+	old := cm.cli
+	cm.cli = cli
+	cm.mu.Unlock()
+
+	if old != nil {
+		old.Close()
+	}
+	return nil
+}
+
+// WaitReady 确认 Docker 服务可用，ping 失败时重建客户端并按指数退避重试，
+// 直到服务恢复、超过 reconnectMaxWait 或 ctx 取消
+func (cm *ClientManager) WaitReady(ctx context.Context) error {
+	err := cm.Ping(ctx)
+	if err == nil {
+		return nil
+	}
+
+	deadline := time.Now().Add(reconnectMaxWait)
+	backoff := time.Second
+	for attempt := 1; ; attempt++ {
+		if time.Now().Add(backoff).After(deadline) {
+			return fmt.Errorf("等待 Docker 服务恢复超时（%v）: %w", reconnectMaxWait, err)
+		}
+		logger.Warn("Docker 服务不可用，%v 后第 %d 次重连: %v", backoff, attempt, err)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+
+		if err = cm.Reconnect(); err == nil {
+			if err = cm.Ping(ctx); err == nil {
+				logger.Info("已重新连接到 Docker 服务")
+				return nil
+			}
+		}
+		backoff = min(backoff*2, reconnectMaxBackoff)
+	}
+}
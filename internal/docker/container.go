@@ -3,6 +3,7 @@ package docker
 import (
 	"context"
 	"fmt"
+	"path"
 	"strings"
 	"time"
 
@@ -13,7 +14,9 @@ import (
 	dockerTypes "github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/mount"
 	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/errdefs"
 )
 
 // ContainerService 容器服务
@@ -31,15 +34,34 @@ func NewContainerService(clientManager *ClientManager) *ContainerService {
 // createContainerInfo 创建容器信息结构体
 func (cs *ContainerService) createContainerInfo(container dockerTypes.Container, name string) types.ContainerInfo {
 	return types.ContainerInfo{
-		ID:     container.ID[:12], // 使用短ID
-		Name:   name,
-		Image:  container.Image,
-		Labels: container.Labels,
-		State:  container.State,
+		ID:      utils.ShortID(container.ID), // 使用短ID
+		Name:    name,
+		Image:   container.Image,
+		ImageID: container.ImageID,
+		Labels:  container.Labels,
+		State:   container.State,
+		Created: time.Unix(container.Created, 0),
 	}
 }
 
-// GetByName 根据容器名称获取容器信息
+// primaryName 获取容器的主名称，Names 为空时使用短ID兜底。
+// 通过 --link 被其他容器引用时 Names 中还会有 /web/db 形式的别名，不能作为容器名称使用
+func (cs *ContainerService) primaryName(container dockerTypes.Container) string {
+	if len(container.Names) == 0 {
+		return utils.ShortID(container.ID)
+	}
+
+	for _, name := range container.Names {
+		// 移除开头的斜杠
+		name = strings.TrimPrefix(name, "/")
+		if !strings.Contains(name, "/") {
+			return name
+		}
+	}
+	return strings.TrimPrefix(container.Names[0], "/")
+}
+
+// GetByName 根据容器名称获取容器信息，名称支持通配符（如 web-*）
 func (cs *ContainerService) GetByName(ctx context.Context, containerNames []string, includeStopped bool) ([]types.ContainerInfo, error) {
 	cli := cs.clientManager.GetClient()
 
@@ -52,21 +74,25 @@ func (cs *ContainerService) GetByName(ctx context.Context, containerNames []stri
 	}
 
 	var result []types.ContainerInfo
+	added := make(map[string]struct{})
 	for _, container := range containers {
-		// 检查容器名称是否匹配（支持部分匹配）
+		// 检查容器名称是否匹配（一个容器可能有多个名称）
 		for _, name := range container.Names {
+			if _, exists := added[container.ID]; exists {
+				break // 同一容器只加入一次
+			}
+
 			// 移除开头的斜杠进行匹配
 			normalizedName := name
 			if len(normalizedName) > 0 && normalizedName[0] == '/' {
 				normalizedName = normalizedName[1:]
 			}
 
-			for _, containerName := range containerNames {
-				if normalizedName == containerName {
-					containerInfo := cs.createContainerInfo(container, normalizedName)
-					result = append(result, containerInfo)
-					break // 找到匹配后跳出内层循环
-				}
+			// 任一名称命中即可，但记录主名称，link 别名无法用于重建容器
+			if MatchName(containerNames, normalizedName) {
+				containerInfo := cs.createContainerInfo(container, cs.primaryName(container))
+				result = append(result, containerInfo)
+				added[container.ID] = struct{}{}
 			}
 		}
 	}
@@ -74,6 +100,23 @@ func (cs *ContainerService) GetByName(ctx context.Context, containerNames []stri
 	return result, nil
 }
 
+// MatchName 判断容器名称是否与任一名称或通配符模式匹配
+func MatchName(patterns []string, name string) bool {
+	if utils.SliceContains(patterns, name) {
+		return true
+	}
+
+	for _, pattern := range patterns {
+		if !strings.ContainsAny(pattern, "*?[") {
+			continue
+		}
+		if matched, err := path.Match(pattern, name); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
+
 // GetByLabel 根据标签获取容器信息
 func (cs *ContainerService) GetByLabel(ctx context.Context, labelKey, labelValue string, includeStopped bool) ([]types.ContainerInfo, error) {
 	cli := cs.clientManager.GetClient()
@@ -97,13 +140,7 @@ func (cs *ContainerService) GetByLabel(ctx context.Context, labelKey, labelValue
 
 	var result []types.ContainerInfo
 	for _, container := range containers {
-		// 移除开头的斜杠进行匹配
-		normalizedName := container.Names[0]
-		if len(normalizedName) > 0 && normalizedName[0] == '/' {
-			normalizedName = normalizedName[1:]
-		}
-
-		containerInfo := cs.createContainerInfo(container, normalizedName)
+		containerInfo := cs.createContainerInfo(container, cs.primaryName(container))
 		result = append(result, containerInfo)
 	}
 
@@ -114,7 +151,7 @@ func (cs *ContainerService) GetByLabel(ctx context.Context, labelKey, labelValue
 func (cs *ContainerService) StopContainer(ctx context.Context, containerID string, timeout *time.Duration) error {
 	cli := cs.clientManager.GetClient()
 
-	logger.Debug("正在停止容器: %s", containerID[:12])
+	logger.Debug("正在停止容器: %s", utils.ShortID(containerID))
 
 	stopOptions := container.StopOptions{}
 	if timeout != nil {
@@ -123,11 +160,11 @@ func (cs *ContainerService) StopContainer(ctx context.Context, containerID strin
 	}
 
 	if err := cli.ContainerStop(ctx, containerID, stopOptions); err != nil {
-		logger.Error("停止容器 %s 失败: %v", containerID[:12], err)
-		return fmt.Errorf("停止容器 %s 失败: %w", containerID[:12], err)
+		logger.Error("停止容器 %s 失败: %v", utils.ShortID(containerID), err)
+		return fmt.Errorf("停止容器 %s 失败: %w", utils.ShortID(containerID), err)
 	}
 
-	logger.Debug("容器 %s 已成功停止", containerID[:12])
+	logger.Debug("容器 %s 已成功停止", utils.ShortID(containerID))
 	return nil
 }
 
@@ -135,18 +172,18 @@ func (cs *ContainerService) StopContainer(ctx context.Context, containerID strin
 func (cs *ContainerService) RemoveContainer(ctx context.Context, containerID string, force bool) error {
 	cli := cs.clientManager.GetClient()
 
-	logger.Debug("正在删除容器: %s", containerID[:12])
+	logger.Debug("正在删除容器: %s", utils.ShortID(containerID))
 
 	removeOptions := container.RemoveOptions{
 		Force: force,
 	}
 
 	if err := cli.ContainerRemove(ctx, containerID, removeOptions); err != nil {
-		logger.Error("删除容器 %s 失败: %v", containerID[:12], err)
-		return fmt.Errorf("删除容器 %s 失败: %w", containerID[:12], err)
+		logger.Error("删除容器 %s 失败: %v", utils.ShortID(containerID), err)
+		return fmt.Errorf("删除容器 %s 失败: %w", utils.ShortID(containerID), err)
 	}
 
-	logger.Debug("容器 %s 已成功删除", containerID[:12])
+	logger.Debug("容器 %s 已成功删除", utils.ShortID(containerID))
 	return nil
 }
 
@@ -154,14 +191,78 @@ func (cs *ContainerService) RemoveContainer(ctx context.Context, containerID str
 func (cs *ContainerService) StartContainer(ctx context.Context, containerID string) error {
 	cli := cs.clientManager.GetClient()
 
-	logger.Debug("正在启动容器: %s", containerID[:12])
+	logger.Debug("正在启动容器: %s", utils.ShortID(containerID))
 
 	if err := cli.ContainerStart(ctx, containerID, container.StartOptions{}); err != nil {
-		logger.Error("启动容器 %s 失败: %v", containerID[:12], err)
-		return fmt.Errorf("启动容器 %s 失败: %w", containerID[:12], err)
+		logger.Error("启动容器 %s 失败: %v", utils.ShortID(containerID), err)
+		return fmt.Errorf("启动容器 %s 失败: %w", utils.ShortID(containerID), err)
 	}
 
-	logger.Debug("容器 %s 已成功启动", containerID[:12])
+	logger.Debug("容器 %s 已成功启动", utils.ShortID(containerID))
+	return nil
+}
+
+// PauseContainer 暂停容器
+func (cs *ContainerService) PauseContainer(ctx context.Context, containerID string) error {
+	cli := cs.clientManager.GetClient()
+
+	logger.Debug("正在暂停容器: %s", utils.ShortID(containerID))
+
+	if err := cli.ContainerPause(ctx, containerID); err != nil {
+		logger.Error("暂停容器 %s 失败: %v", utils.ShortID(containerID), err)
+		return fmt.Errorf("暂停容器 %s 失败: %w", utils.ShortID(containerID), err)
+	}
+
+	logger.Debug("容器 %s 已成功暂停", utils.ShortID(containerID))
+	return nil
+}
+
+// UnpauseContainer 恢复已暂停的容器
+func (cs *ContainerService) UnpauseContainer(ctx context.Context, containerID string) error {
+	cli := cs.clientManager.GetClient()
+
+	logger.Debug("正在恢复暂停的容器: %s", utils.ShortID(containerID))
+
+	if err := cli.ContainerUnpause(ctx, containerID); err != nil {
+		logger.Error("恢复暂停的容器 %s 失败: %v", utils.ShortID(containerID), err)
+		return fmt.Errorf("恢复暂停的容器 %s 失败: %w", utils.ShortID(containerID), err)
+	}
+
+	logger.Debug("容器 %s 已恢复运行", utils.ShortID(containerID))
+	return nil
+}
+
+// RenameContainer 重命名容器，容器已是目标名称时视为成功
+func (cs *ContainerService) RenameContainer(ctx context.Context, containerID, newName string) error {
+	cli := cs.clientManager.GetClient()
+
+	logger.Debug("正在重命名容器 %s 为 %s", utils.ShortID(containerID), newName)
+
+	if err := cli.ContainerRename(ctx, containerID, newName); err != nil {
+		// 守护进程对同名重命名返回 InvalidParameter 类型的 "Renaming a container with the same name as its current name"
+		if errdefs.IsInvalidParameter(err) && strings.Contains(err.Error(), "same name as its current name") {
+			logger.Debug("容器 %s 已命名为 %s，无需重命名", utils.ShortID(containerID), newName)
+			return nil
+		}
+		logger.Error("重命名容器 %s 失败: %v", utils.ShortID(containerID), err)
+		return fmt.Errorf("重命名容器 %s 失败: %w", utils.ShortID(containerID), err)
+	}
+
+	logger.Debug("容器 %s 已重命名为 %s", utils.ShortID(containerID), newName)
+	return nil
+}
+
+// UpdateContainerResources 通过 docker update 热更新容器的资源限制，无需重建容器
+func (cs *ContainerService) UpdateContainerResources(ctx context.Context, containerID string, resources container.Resources) error {
+	cli := cs.clientManager.GetClient()
+
+	logger.Debug("正在更新容器 %s 的资源限制", utils.ShortID(containerID))
+
+	if _, err := cli.ContainerUpdate(ctx, containerID, container.UpdateConfig{Resources: resources}); err != nil {
+		return fmt.Errorf("更新容器 %s 的资源限制失败: %w", utils.ShortID(containerID), err)
+	}
+
+	logger.Debug("容器 %s 的资源限制已更新", utils.ShortID(containerID))
 	return nil
 }
 
@@ -177,10 +278,73 @@ func (cs *ContainerService) CreateContainer(ctx context.Context, config *contain
 		return "", fmt.Errorf("创建容器 %s 失败: %w", containerName, err)
 	}
 
-	logger.Debug("容器 %s 已成功创建，ID: %s", containerName, resp.ID[:12])
+	logger.Debug("容器 %s 已成功创建，ID: %s", containerName, utils.ShortID(resp.ID))
 	return resp.ID, nil
 }
 
+// RecreateContainer 按旧容器的完整配置使用新镜像创建容器，普通更新和自我更新共用。
+// HostConfig 原样沿用旧容器的配置（重启策略、AutoRemove、Privileged、SecurityOpt、GroupAdd 等均保留），
+// inheritEntrypoint 为 false 时改用新镜像默认的 entrypoint 和 healthcheck。
+// 容器已创建但后续步骤失败时仍返回新容器ID以便清理
+func (cs *ContainerService) RecreateContainer(ctx context.Context, containerJSON *dockerTypes.ContainerJSON, imageInfo *dockerTypes.ImageInspect, newImage string, containerName string, inheritEntrypoint bool) (string, error) {
+	// 准备创建容器的配置
+	config := cs.GetCreateConfig(ctx, *containerJSON, imageInfo, newImage, inheritEntrypoint)
+	hostConfig := cs.GetCreateHostConfig(ctx, *containerJSON)
+	networkingConfig := cs.GetNetworkConfig(ctx, *containerJSON)
+
+	// 仅使用一个网络配置来创建容器，之后再连接其他网络
+	simpleNetworkConfig := func() *network.NetworkingConfig {
+		oneEndpoint := make(map[string]*network.EndpointSettings)
+		for k, v := range networkingConfig.EndpointsConfig {
+			oneEndpoint[k] = v
+			break
+		}
+		return &network.NetworkingConfig{EndpointsConfig: oneEndpoint}
+	}()
+
+	// 创建新容器
+	newContainerID, err := cs.CreateContainer(ctx, config, hostConfig, simpleNetworkConfig, containerName)
+	if err != nil {
+		return "", err
+	}
+
+	// 连接其他网络
+	if !(hostConfig.NetworkMode.IsHost()) {
+		for k := range simpleNetworkConfig.EndpointsConfig {
+			err = cs.NetworkDisconnect(ctx, k, newContainerID, true)
+			if err != nil {
+				return newContainerID, err
+			}
+		}
+
+		for k, v := range networkingConfig.EndpointsConfig {
+			err = cs.NetworkConnect(ctx, k, newContainerID, v)
+			if err != nil {
+				return newContainerID, err
+			}
+		}
+	}
+
+	return newContainerID, nil
+}
+
+// GetStartedAt 获取容器最近一次启动的时间，容器从未启动过时返回零值
+func (cs *ContainerService) GetStartedAt(ctx context.Context, containerID string) (time.Time, error) {
+	containerJSON, err := cs.GetContainerConfig(ctx, containerID)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if containerJSON.State == nil || containerJSON.State.StartedAt == "" {
+		return time.Time{}, nil
+	}
+
+	startedAt, err := time.Parse(time.RFC3339Nano, containerJSON.State.StartedAt)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("解析容器 %s 的启动时间失败: %w", utils.ShortID(containerID), err)
+	}
+	return startedAt, nil
+}
+
 // GetAll 获取所有容器信息
 func (cs *ContainerService) GetAll(ctx context.Context, includeStopped bool) ([]types.ContainerInfo, error) {
 	cli := cs.clientManager.GetClient()
@@ -195,13 +359,7 @@ func (cs *ContainerService) GetAll(ctx context.Context, includeStopped bool) ([]
 
 	var result []types.ContainerInfo
 	for _, container := range containers {
-		// 使用第一个名称作为容器名称
-		normalizedName := container.Names[0]
-		if len(normalizedName) > 0 && normalizedName[0] == '/' {
-			normalizedName = normalizedName[1:]
-		}
-
-		containerInfo := cs.createContainerInfo(container, normalizedName)
+		containerInfo := cs.createContainerInfo(container, cs.primaryName(container))
 		result = append(result, containerInfo)
 	}
 
@@ -214,8 +372,8 @@ func (cs *ContainerService) GetContainerConfig(ctx context.Context, containerID
 
 	containerJSON, err := cli.ContainerInspect(ctx, containerID)
 	if err != nil {
-		logger.Error("获取容器 %s 配置失败: %v", containerID[:12], err)
-		return nil, fmt.Errorf("获取容器 %s 配置失败: %w", containerID[:12], err)
+		logger.Error("获取容器 %s 配置失败: %v", utils.ShortID(containerID), err)
+		return nil, fmt.Errorf("获取容器 %s 配置失败: %w", utils.ShortID(containerID), err)
 	}
 
 	return &containerJSON, nil
@@ -233,11 +391,18 @@ func (cs *ContainerService) GetImageInspect(ctx context.Context, imageName strin
 	return &imageInfo, nil
 }
 
-func (cs *ContainerService) GetCreateConfig(ctx context.Context, containerJSON dockerTypes.ContainerJSON, imageInfo *dockerTypes.ImageInspect, imageName string) *container.Config {
+// GetCreateConfig 生成新容器的配置，inheritEntrypoint 为 true 时保留容器显式覆盖的 entrypoint/cmd/healthcheck
+func (cs *ContainerService) GetCreateConfig(ctx context.Context, containerJSON dockerTypes.ContainerJSON, imageInfo *dockerTypes.ImageInspect, imageName string, inheritEntrypoint bool) *container.Config {
 	config := containerJSON.Config
 	hostConfig := containerJSON.HostConfig
 	imageConfig := imageInfo.Config
 
+	// 与旧镜像的默认值比对才能识别出显式覆盖，旧镜像已被删除时退回与新镜像比对
+	baseConfig := imageConfig
+	if oldImage, _, err := cs.clientManager.GetClient().ImageInspectWithRaw(ctx, containerJSON.Image); err == nil && oldImage.Config != nil {
+		baseConfig = oldImage.Config
+	}
+
 	if config.WorkingDir == imageConfig.WorkingDir {
 		config.WorkingDir = ""
 	}
@@ -250,32 +415,38 @@ func (cs *ContainerService) GetCreateConfig(ctx context.Context, containerJSON d
 		config.Hostname = ""
 	}
 
-	if utils.SliceEqual(config.Entrypoint, imageConfig.Entrypoint) {
+	// 不继承时 entrypoint 和 cmd 都改用新镜像的默认值，显式覆盖的 cmd 也一并丢弃
+	if !inheritEntrypoint {
 		config.Entrypoint = nil
-		if utils.SliceEqual(config.Cmd, imageConfig.Cmd) {
+		config.Cmd = nil
+	} else if utils.SliceEqual(config.Entrypoint, baseConfig.Entrypoint) {
+		config.Entrypoint = nil
+		if utils.SliceEqual(config.Cmd, baseConfig.Cmd) {
 			config.Cmd = nil
 		}
 	}
 
 	// Clear HEALTHCHECK configuration (if default)
-	if config.Healthcheck != nil && imageConfig.Healthcheck != nil {
-		if utils.SliceEqual(config.Healthcheck.Test, imageConfig.Healthcheck.Test) {
+	if !inheritEntrypoint {
+		config.Healthcheck = nil
+	} else if config.Healthcheck != nil && baseConfig.Healthcheck != nil {
+		if utils.SliceEqual(config.Healthcheck.Test, baseConfig.Healthcheck.Test) {
 			config.Healthcheck.Test = nil
 		}
 
-		if config.Healthcheck.Retries == imageConfig.Healthcheck.Retries {
+		if config.Healthcheck.Retries == baseConfig.Healthcheck.Retries {
 			config.Healthcheck.Retries = 0
 		}
 
-		if config.Healthcheck.Interval == imageConfig.Healthcheck.Interval {
+		if config.Healthcheck.Interval == baseConfig.Healthcheck.Interval {
 			config.Healthcheck.Interval = 0
 		}
 
-		if config.Healthcheck.Timeout == imageConfig.Healthcheck.Timeout {
+		if config.Healthcheck.Timeout == baseConfig.Healthcheck.Timeout {
 			config.Healthcheck.Timeout = 0
 		}
 
-		if config.Healthcheck.StartPeriod == imageConfig.Healthcheck.StartPeriod {
+		if config.Healthcheck.StartPeriod == baseConfig.Healthcheck.StartPeriod {
 			config.Healthcheck.StartPeriod = 0
 		}
 	}
@@ -310,19 +481,75 @@ func (cs *ContainerService) GetCreateHostConfig(ctx context.Context, containerJS
 		hostConfig.Links[i] = fmt.Sprintf("%s:%s", name, alias)
 	}
 
+	cs.migrateVolumeMounts(ctx, containerJSON, hostConfig)
+
 	return hostConfig
 }
 
+// migrateVolumeMounts 把旧容器挂载、但未出现在 Binds/Mounts/VolumesFrom 中的卷（主要是镜像 VOLUME 声明时自动创建的匿名卷）
+// 以 Mounts 的形式显式挂载到新容器，否则新容器会重新创建空的匿名卷，旧数据随旧容器一起被遗弃
+func (cs *ContainerService) migrateVolumeMounts(ctx context.Context, containerJSON dockerTypes.ContainerJSON, hostConfig *container.HostConfig) {
+	covered := make(map[string]struct{})
+	for _, bind := range hostConfig.Binds {
+		if parts := strings.Split(bind, ":"); len(parts) >= 2 {
+			covered[path.Clean(parts[1])] = struct{}{}
+		}
+	}
+	for _, m := range hostConfig.Mounts {
+		covered[path.Clean(m.Target)] = struct{}{}
+	}
+	for target := range hostConfig.Tmpfs {
+		covered[path.Clean(target)] = struct{}{}
+	}
+
+	// --volumes-from 继承的卷由来源容器提供，不能重复挂载
+	for _, from := range hostConfig.VolumesFrom {
+		name, _, _ := strings.Cut(from, ":")
+		source, err := cs.clientManager.GetClient().ContainerInspect(ctx, name)
+		if err != nil {
+			logger.Warn("获取 volumes-from 容器 %s 信息失败，跳过卷迁移: %v", name, err)
+			return
+		}
+		for _, m := range source.Mounts {
+			covered[path.Clean(m.Destination)] = struct{}{}
+		}
+	}
+
+	for _, m := range containerJSON.Mounts {
+		if m.Type != mount.TypeVolume || m.Name == "" {
+			continue
+		}
+		if _, ok := covered[path.Clean(m.Destination)]; ok {
+			continue
+		}
+
+		hostConfig.Mounts = append(hostConfig.Mounts, mount.Mount{
+			Type:     mount.TypeVolume,
+			Source:   m.Name,
+			Target:   m.Destination,
+			ReadOnly: !m.RW,
+		})
+		covered[path.Clean(m.Destination)] = struct{}{}
+		logger.Debug("容器 %s 的卷 %s 将挂载到新容器的 %s", containerJSON.Name, utils.ShortID(m.Name), m.Destination)
+	}
+}
+
+// GetNetworkConfig 生成新容器的网络配置，只保留创建时指定的网络别名、静态 IP（IPAMConfig）、links 和驱动参数，
+// 运行时分配的 EndpointID、IP、网关等字段不带入，由 Docker 重新分配
 func (cs *ContainerService) GetNetworkConfig(ctx context.Context, containerJSON dockerTypes.ContainerJSON) *network.NetworkingConfig {
 	config := &network.NetworkingConfig{
-		EndpointsConfig: containerJSON.NetworkSettings.Networks,
+		EndpointsConfig: make(map[string]*network.EndpointSettings, len(containerJSON.NetworkSettings.Networks)),
 	}
 
-	// Remove the old container ID alias from the network aliases, as it would accumulate across updates otherwise
-	for _, ep := range config.EndpointsConfig {
-		cidAlias := containerJSON.ID[:12]
-		aliases := make([]string, 0, len(ep.Aliases))
+	cidAlias := utils.ShortID(containerJSON.ID)
+	for name, ep := range containerJSON.NetworkSettings.Networks {
+		if ep == nil {
+			config.EndpointsConfig[name] = &network.EndpointSettings{}
+			continue
+		}
 
+		// Remove the old container ID alias from the network aliases, as it would accumulate across updates otherwise
+		aliases := make([]string, 0, len(ep.Aliases))
 		for _, alias := range ep.Aliases {
 			if alias == cidAlias {
 				continue
@@ -330,30 +557,70 @@ func (cs *ContainerService) GetNetworkConfig(ctx context.Context, containerJSON
 			aliases = append(aliases, alias)
 		}
 
-		ep.Aliases = aliases
+		endpoint := &network.EndpointSettings{
+			Aliases:    aliases,
+			Links:      ep.Links,
+			DriverOpts: ep.DriverOpts,
+		}
+		if ep.IPAMConfig != nil {
+			ipam := *ep.IPAMConfig
+			endpoint.IPAMConfig = &ipam
+			logger.Debug("容器 %s 在网络 %s 中保留静态 IP: %s %s", containerJSON.Name, name, ipam.IPv4Address, ipam.IPv6Address)
+		}
+		config.EndpointsConfig[name] = endpoint
 	}
 
 	return config
 }
 
+// isNoSuchContainer 判断错误是否为容器不存在，守护进程返回 NotFound 类型的 "No such container: <id>"，
+// 用于与同为 NotFound 类型的网络不存在区分
+func isNoSuchContainer(err error) bool {
+	return errdefs.IsNotFound(err) && strings.Contains(err.Error(), "No such container")
+}
+
+// NetworkDisconnect 断开容器与网络的连接，网络不存在或容器未连接到该网络时视为成功，容器不存在时返回错误
 func (cs *ContainerService) NetworkDisconnect(ctx context.Context, networkID, containerID string, force bool) error {
 	cli := cs.clientManager.GetClient()
 
+	logger.Debug("正在断开容器 %s 与网络 %s 的连接", utils.ShortID(containerID), networkID)
+
 	if err := cli.NetworkDisconnect(ctx, networkID, containerID, force); err != nil {
-		logger.Error("断开容器 %s 与网络 %s 的连接失败: %v", containerID[:12], networkID, err)
-		return fmt.Errorf("断开容器 %s 与网络 %s 的连接失败: %w", containerID[:12], networkID, err)
+		// 网络不存在时守护进程返回 NotFound 类型的 "network <id> not found"；
+		// 容器未连接时返回 "container <id> is not connected to network <id>"，不同版本的错误类型不一致，只能按消息判断
+		if (errdefs.IsNotFound(err) && !isNoSuchContainer(err)) || strings.Contains(err.Error(), "is not connected") {
+			logger.Debug("容器 %s 未连接到网络 %s，无需断开: %v", utils.ShortID(containerID), networkID, err)
+			return nil
+		}
+		logger.Error("断开容器 %s 与网络 %s 的连接失败: %v", utils.ShortID(containerID), networkID, err)
+		return fmt.Errorf("断开容器 %s 与网络 %s 的连接失败: %w", utils.ShortID(containerID), networkID, err)
 	}
 
+	logger.Debug("容器 %s 已断开与网络 %s 的连接", utils.ShortID(containerID), networkID)
 	return nil
 }
 
+// NetworkConnect 将容器连接到网络，容器已连接到该网络时视为成功，网络不存在时返回错误
 func (cs *ContainerService) NetworkConnect(ctx context.Context, networkID, containerID string, endpointConfig *network.EndpointSettings) error {
 	cli := cs.clientManager.GetClient()
 
+	logger.Debug("正在连接容器 %s 到网络 %s", utils.ShortID(containerID), networkID)
+
 	if err := cli.NetworkConnect(ctx, networkID, containerID, endpointConfig); err != nil {
-		logger.Error("连接容器 %s 到网络 %s 失败: %v", containerID[:12], networkID, err)
-		return fmt.Errorf("连接容器 %s 到网络 %s 失败: %w", containerID[:12], networkID, err)
+		// 重复连接时 libnetwork 返回 "endpoint with name <container> already exists in network <network>"，
+		// 该错误在不同版本中被包装为 Forbidden 或 Conflict，只能按消息判断
+		if strings.Contains(err.Error(), "already exists in network") {
+			logger.Debug("容器 %s 已连接到网络 %s，无需重复连接", utils.ShortID(containerID), networkID)
+			return nil
+		}
+		if errdefs.IsNotFound(err) && !isNoSuchContainer(err) {
+			logger.Error("网络 %s 不存在，无法连接容器 %s", networkID, utils.ShortID(containerID))
+			return fmt.Errorf("网络 %s 不存在: %w", networkID, err)
+		}
+		logger.Error("连接容器 %s 到网络 %s 失败: %v", utils.ShortID(containerID), networkID, err)
+		return fmt.Errorf("连接容器 %s 到网络 %s 失败: %w", utils.ShortID(containerID), networkID, err)
 	}
 
+	logger.Debug("容器 %s 已连接到网络 %s", utils.ShortID(containerID), networkID)
 	return nil
 }
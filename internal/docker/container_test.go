@@ -0,0 +1,282 @@
+package docker
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"watchducker/internal/docker/dockertest"
+	"watchducker/pkg/utils"
+
+	dockerTypes "github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/errdefs"
+)
+
+func TestGetByNameMultipleNames(t *testing.T) {
+	cm := newFakeClientManager(t, map[string]http.HandlerFunc{
+		"GET /containers/json": func(w http.ResponseWriter, r *http.Request) {
+			dockertest.WriteJSON(w, http.StatusOK, []dockerTypes.Container{
+				// 被 web 通过 --link 引用的容器，link 别名排在主名称之前
+				{ID: "db0123456789abcdef", Names: []string{"/web/db", "/db", "/api/db"}, Image: "postgres:16", State: "running"},
+				{ID: "web0123456789abcdef", Names: []string{"/web"}, Image: "nginx:latest", State: "running"},
+				{ID: "cache0123456789abcd", Names: []string{"/cache"}, Image: "redis:7", State: "running"},
+			})
+		},
+	})
+
+	tests := []struct {
+		name     string
+		patterns []string
+		want     []string
+	}{
+		{name: "多个名称同时命中", patterns: []string{"*db"}, want: []string{"db"}},
+		{name: "多个模式命中同一容器", patterns: []string{"db", "web/db", "api/*"}, want: []string{"db"}},
+		{name: "与其他容器一起命中", patterns: []string{"*"}, want: []string{"db", "web", "cache"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			containers, err := NewContainerService(cm).GetByName(context.Background(), tt.patterns, false)
+			if err != nil {
+				t.Fatalf("GetByName 返回错误: %v", err)
+			}
+
+			var got []string
+			for _, c := range containers {
+				got = append(got, c.Name)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("容器名称 = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("容器名称 = %v, want %v", got, tt.want)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestGetImageInspect(t *testing.T) {
+	cm := newFakeClientManager(t, map[string]http.HandlerFunc{
+		"GET /images/{name}/json": func(w http.ResponseWriter, r *http.Request) {
+			if r.PathValue("name") != "nginx:latest" {
+				dockertest.WriteError(w, http.StatusNotFound, "No such image: "+r.PathValue("name"))
+				return
+			}
+			dockertest.WriteJSON(w, http.StatusOK, dockerTypes.ImageInspect{
+				ID:     "sha256:abc",
+				Config: &container.Config{Entrypoint: []string{"/docker-entrypoint.sh"}},
+			})
+		},
+	})
+	cs := NewContainerService(cm)
+
+	info, err := cs.GetImageInspect(context.Background(), "nginx:latest")
+	if err != nil {
+		t.Fatalf("GetImageInspect 返回错误: %v", err)
+	}
+	if info.ID != "sha256:abc" {
+		t.Errorf("ID = %q, want sha256:abc", info.ID)
+	}
+	if info.Config == nil || len(info.Config.Entrypoint) != 1 || info.Config.Entrypoint[0] != "/docker-entrypoint.sh" {
+		t.Errorf("Config 未正确返回: %+v", info.Config)
+	}
+
+	_, err = cs.GetImageInspect(context.Background(), "missing:latest")
+	if err == nil {
+		t.Fatal("镜像不存在时应返回错误")
+	}
+	if !errdefs.IsNotFound(err) {
+		t.Errorf("错误应保留 NotFound 类型: %v", err)
+	}
+}
+
+func TestRenameContainer(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		message string
+		wantErr bool
+	}{
+		{name: "成功", status: http.StatusNoContent},
+		{name: "与当前名称相同", status: http.StatusBadRequest, message: "Renaming a container with the same name as its current name"},
+		{name: "名称已被占用", status: http.StatusConflict, message: `Conflict. The container name "/web" is already in use`, wantErr: true},
+		{name: "容器不存在", status: http.StatusNotFound, message: "No such container: abc", wantErr: true},
+		{name: "其他参数错误", status: http.StatusBadRequest, message: "Invalid container name (web/db)", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotName string
+			cm := newFakeClientManager(t, map[string]http.HandlerFunc{
+				"POST /containers/{id}/rename": func(w http.ResponseWriter, r *http.Request) {
+					gotName = r.URL.Query().Get("name")
+					if tt.message != "" {
+						dockertest.WriteError(w, tt.status, tt.message)
+						return
+					}
+					w.WriteHeader(tt.status)
+				},
+			})
+
+			err := NewContainerService(cm).RenameContainer(context.Background(), "abc", "web")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("RenameContainer 错误 = %v, wantErr %v", err, tt.wantErr)
+			}
+			if gotName != "web" {
+				t.Errorf("请求的新名称 = %q, want web", gotName)
+			}
+		})
+	}
+}
+
+func TestGetNetworkConfig(t *testing.T) {
+	const containerID = "0123456789abcdef0123"
+
+	tests := []struct {
+		name     string
+		endpoint *network.EndpointSettings
+		want     *network.EndpointSettings
+	}{
+		{
+			name: "保留别名、静态 IP、links 和驱动参数",
+			endpoint: &network.EndpointSettings{
+				Aliases:    []string{"web", utils.ShortID(containerID), "frontend"},
+				Links:      []string{"db:database"},
+				DriverOpts: map[string]string{"com.example.opt": "1"},
+				IPAMConfig: &network.EndpointIPAMConfig{IPv4Address: "172.20.0.10", IPv6Address: "fd00::10"},
+				NetworkID:  "net123",
+				EndpointID: "ep123",
+				IPAddress:  "172.20.0.10",
+				Gateway:    "172.20.0.1",
+				MacAddress: "02:42:ac:14:00:0a",
+			},
+			want: &network.EndpointSettings{
+				Aliases:    []string{"web", "frontend"},
+				Links:      []string{"db:database"},
+				DriverOpts: map[string]string{"com.example.opt": "1"},
+				IPAMConfig: &network.EndpointIPAMConfig{IPv4Address: "172.20.0.10", IPv6Address: "fd00::10"},
+			},
+		},
+		{
+			name: "动态分配的 IP 不保留",
+			endpoint: &network.EndpointSettings{
+				Aliases:    []string{utils.ShortID(containerID)},
+				EndpointID: "ep456",
+				IPAddress:  "172.20.0.11",
+				Gateway:    "172.20.0.1",
+			},
+			want: &network.EndpointSettings{Aliases: []string{}},
+		},
+		{
+			name:     "空的网络配置",
+			endpoint: nil,
+			want:     &network.EndpointSettings{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			containerJSON := dockerTypes.ContainerJSON{
+				ContainerJSONBase: &dockerTypes.ContainerJSONBase{ID: containerID, Name: "/web"},
+				NetworkSettings: &dockerTypes.NetworkSettings{
+					Networks: map[string]*network.EndpointSettings{"app": tt.endpoint},
+				},
+			}
+
+			config := (&ContainerService{}).GetNetworkConfig(context.Background(), containerJSON)
+			got := config.EndpointsConfig["app"]
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("EndpointSettings = %+v, want %+v", got, tt.want)
+			}
+
+			// IPAMConfig 需深拷贝，修改新配置不能影响旧容器的配置
+			if tt.endpoint != nil && tt.endpoint.IPAMConfig != nil {
+				if got.IPAMConfig == tt.endpoint.IPAMConfig {
+					t.Fatal("IPAMConfig 未深拷贝")
+				}
+				got.IPAMConfig.IPv4Address = "10.0.0.1"
+				if tt.endpoint.IPAMConfig.IPv4Address != "172.20.0.10" {
+					t.Error("修改新配置的 IPAMConfig 影响了旧容器的配置")
+				}
+			}
+		})
+	}
+}
+
+func TestGetCreateConfigEntrypoint(t *testing.T) {
+	imageConfig := &container.Config{
+		Entrypoint:  []string{"/docker-entrypoint.sh"},
+		Cmd:         []string{"nginx", "-g", "daemon off;"},
+		Healthcheck: &container.HealthConfig{Test: []string{"CMD", "curl", "-f", "http://localhost"}},
+	}
+	cm := newFakeClientManager(t, map[string]http.HandlerFunc{
+		"GET /images/{name}/json": func(w http.ResponseWriter, r *http.Request) {
+			dockertest.WriteJSON(w, http.StatusOK, dockerTypes.ImageInspect{ID: "sha256:old", Config: imageConfig})
+		},
+	})
+
+	tests := []struct {
+		name           string
+		entrypoint     []string
+		cmd            []string
+		inherit        bool
+		wantEntrypoint []string
+		wantCmd        []string
+	}{
+		{
+			name:       "继承时保留显式覆盖的 cmd",
+			entrypoint: imageConfig.Entrypoint, cmd: []string{"nginx", "-T"},
+			inherit: true, wantCmd: []string{"nginx", "-T"},
+		},
+		{
+			name:       "继承时清除与镜像相同的默认值",
+			entrypoint: imageConfig.Entrypoint, cmd: imageConfig.Cmd,
+			inherit: true,
+		},
+		{
+			name:       "继承时保留显式覆盖的 entrypoint 和 cmd",
+			entrypoint: []string{"/bin/sh", "-c"}, cmd: []string{"echo hi"},
+			inherit: true, wantEntrypoint: []string{"/bin/sh", "-c"}, wantCmd: []string{"echo hi"},
+		},
+		{
+			name:       "不继承时丢弃显式覆盖的 cmd",
+			entrypoint: imageConfig.Entrypoint, cmd: []string{"nginx", "-T"},
+			inherit: false,
+		},
+		{
+			name:       "不继承时丢弃显式覆盖的 entrypoint 和 cmd",
+			entrypoint: []string{"/bin/sh", "-c"}, cmd: []string{"echo hi"},
+			inherit: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			containerJSON := dockerTypes.ContainerJSON{
+				ContainerJSONBase: &dockerTypes.ContainerJSONBase{Image: "sha256:old", HostConfig: &container.HostConfig{}},
+				Config: &container.Config{
+					Entrypoint:  tt.entrypoint,
+					Cmd:         tt.cmd,
+					Healthcheck: &container.HealthConfig{Test: []string{"CMD", "true"}},
+				},
+			}
+
+			config := NewContainerService(cm).GetCreateConfig(context.Background(), containerJSON, &dockerTypes.ImageInspect{Config: imageConfig}, "nginx:latest", tt.inherit)
+			if !reflect.DeepEqual([]string(config.Entrypoint), tt.wantEntrypoint) {
+				t.Errorf("Entrypoint = %v, want %v", config.Entrypoint, tt.wantEntrypoint)
+			}
+			if !reflect.DeepEqual([]string(config.Cmd), tt.wantCmd) {
+				t.Errorf("Cmd = %v, want %v", config.Cmd, tt.wantCmd)
+			}
+			if !tt.inherit && config.Healthcheck != nil {
+				t.Errorf("不继承时 Healthcheck 应使用新镜像的默认值，得到 %+v", config.Healthcheck)
+			}
+		})
+	}
+}
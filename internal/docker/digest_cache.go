@@ -0,0 +1,119 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"watchducker/pkg/logger"
+	"watchducker/pkg/state"
+)
+
+// digestCache 记录每个镜像上次检查时 registry 上的 manifest 摘要和拉取后的镜像摘要，
+// 守护模式下常驻内存，单次模式下通过状态文件持久化
+var digestCache = struct {
+	sync.Mutex
+	images map[string]*state.ImageState
+}{images: make(map[string]*state.ImageState)}
+
+// LoadDigestCache 合并状态文件中的镜像摘要缓存，同一镜像保留检查时间较新的记录
+func LoadDigestCache(images map[string]*state.ImageState) {
+	digestCache.Lock()
+	defer digestCache.Unlock()
+
+	for name, img := range images {
+		if img == nil {
+			continue
+		}
+		if cached, ok := digestCache.images[name]; ok && cached.CheckedAt.After(img.CheckedAt) {
+			continue
+		}
+		entry := *img
+		digestCache.images[name] = &entry
+	}
+}
+
+// DigestCache 返回镜像摘要缓存的副本，用于写入状态文件
+func DigestCache() map[string]*state.ImageState {
+	digestCache.Lock()
+	defer digestCache.Unlock()
+
+	images := make(map[string]*state.ImageState, len(digestCache.images))
+	for name, img := range digestCache.images {
+		entry := *img
+		images[name] = &entry
+	}
+	return images
+}
+
+// cachedRemoteHash 若镜像的 manifest 摘要与上次检查时一致，返回上次拉取得到的镜像摘要
+func cachedRemoteHash(imageName, manifestDigest string) (string, bool) {
+	digestCache.Lock()
+	defer digestCache.Unlock()
+
+	cached, ok := digestCache.images[imageName]
+	if !ok || cached.ManifestDigest != manifestDigest {
+		return "", false
+	}
+	return cached.RemoteHash, true
+}
+
+// platformUnchanged 判断多架构清单变化时本机平台的 manifest 是否与上次拉取时相同，且本地镜像仍是上次拉取的版本
+func platformUnchanged(imageName, platformDigest, localHash string) bool {
+	if platformDigest == "" {
+		return false
+	}
+
+	digestCache.Lock()
+	defer digestCache.Unlock()
+
+	cached, ok := digestCache.images[imageName]
+	return ok && cached.PlatformDigest == platformDigest && cached.RemoteHash == localHash
+}
+
+// storeDigest 记录镜像本次检查的 manifest 摘要、本机平台的 manifest 摘要和拉取后的镜像摘要
+func storeDigest(imageName string, manifest manifestDigests, remoteHash string) {
+	digestCache.Lock()
+	defer digestCache.Unlock()
+
+	digestCache.images[imageName] = &state.ImageState{
+		ManifestDigest: manifest.digest,
+		PlatformDigest: manifest.platform,
+		RemoteHash:     remoteHash,
+		CheckedAt:      time.Now(),
+	}
+}
+
+// GetManifestDigest 通过 registry 的 manifest 请求获取镜像摘要，不拉取镜像层。返回的摘要与 RepoDigest 同类
+// （多架构镜像为清单摘要），两种查询方式结果一致。
+// 优先直接访问 registry API，失败时再通过 Docker 守护进程的 distribution 接口查询
+func (is *ImageService) GetManifestDigest(ctx context.Context, imageName string) (string, error) {
+	ref := is.mirrorReference(imageName)
+
+	digest, err := is.registry.ManifestDigest(ctx, ref)
+	if err == nil {
+		return digest, nil
+	}
+	logger.Debug("直接查询镜像 %s 的 manifest 失败，改为通过 Docker 守护进程查询: %v", ref, err)
+
+	cli := is.clientManager.GetClient()
+	inspect, err := cli.DistributionInspect(ctx, ref, "")
+	if err != nil {
+		return "", fmt.Errorf("获取镜像 manifest 失败: %w", err)
+	}
+
+	return inspect.Descriptor.Digest.String(), nil
+}
+
+// getPlatformDigest 获取 Docker 主机平台对应的 manifest 摘要，只能直接访问 registry 得到，失败时返回空字符串
+func (is *ImageService) getPlatformDigest(ctx context.Context, imageName string) string {
+	ref := is.mirrorReference(imageName)
+
+	digest, err := is.registry.PlatformDigest(ctx, ref, is.hostPlatform(ctx))
+	if err != nil {
+		logger.Debug("获取镜像 %s 本机平台的 manifest 摘要失败: %v", ref, err)
+		return ""
+	}
+	return digest
+}
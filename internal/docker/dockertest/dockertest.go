@@ -0,0 +1,47 @@
+// Package dockertest 提供模拟 Docker Engine API 的 HTTP 服务，供单元测试在没有 Docker 守护进程时使用
+package dockertest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// APIVersion 模拟服务使用的 API 版本，通过 DOCKER_API_VERSION 固定客户端版本，避免版本协商请求
+const APIVersion = "1.45"
+
+// NewServer 启动模拟 Docker Engine API 的 HTTP 服务，返回可传给 docker.NewClientManager 的地址。
+// handlers 的键为去掉版本前缀的路由，如 "GET /images/{name}/json"，未注册的请求会使测试失败
+func NewServer(t *testing.T, handlers map[string]http.HandlerFunc) string {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	for pattern, handler := range handlers {
+		method, path, _ := strings.Cut(pattern, " ")
+		mux.HandleFunc(method+" /v"+APIVersion+path, handler)
+	}
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("未预期的 Docker API 请求: %s %s", r.Method, r.URL.Path)
+		WriteError(w, http.StatusNotImplemented, "not implemented")
+	})
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	t.Setenv("DOCKER_API_VERSION", APIVersion)
+
+	return "tcp://" + srv.Listener.Addr().String()
+}
+
+// WriteJSON 以 JSON 格式写入响应
+func WriteJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// WriteError 按 Docker API 的格式写入错误响应，客户端据状态码转换为对应的 errdefs 类型
+func WriteError(w http.ResponseWriter, status int, message string) {
+	WriteJSON(w, status, map[string]string{"message": message})
+}
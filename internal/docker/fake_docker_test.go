@@ -0,0 +1,20 @@
+package docker
+
+import (
+	"net/http"
+	"testing"
+
+	"watchducker/internal/docker/dockertest"
+)
+
+// newFakeClientManager 返回连接到模拟 Docker Engine API 的 ClientManager
+func newFakeClientManager(t *testing.T, handlers map[string]http.HandlerFunc) *ClientManager {
+	t.Helper()
+
+	cm, err := NewClientManager(dockertest.NewServer(t, handlers))
+	if err != nil {
+		t.Fatalf("创建 Docker 客户端失败: %v", err)
+	}
+	t.Cleanup(func() { cm.Close() })
+	return cm
+}
@@ -1,31 +1,169 @@
 package docker
 
 import (
-	"bufio"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"runtime"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"watchducker/internal/types"
 	"watchducker/pkg/logger"
 
+	"github.com/distribution/reference"
+	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/api/types/filters"
 	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/errdefs"
+	"github.com/docker/docker/pkg/jsonmessage"
+	"golang.org/x/time/rate"
 )
 
+// ErrImageNotFound 本地不存在匹配引用的镜像
+var ErrImageNotFound = errors.New("本地不存在镜像")
+
+// ErrRemoteNotFound registry 上不存在该镜像，通常是本地构建的镜像
+var ErrRemoteNotFound = errors.New("registry 上不存在镜像")
+
+// pullLimiter 全局镜像拉取限流器，所有主机和检查共享，nil 表示不限制
+var pullLimiter *rate.Limiter
+
+// SetPullRateLimit 设置每分钟允许的镜像拉取次数，<=0 表示不限制；相同限额重复设置时保留已有令牌状态
+func SetPullRateLimit(perMinute int) {
+	if perMinute <= 0 {
+		pullLimiter = nil
+		return
+	}
+
+	limit := rate.Every(time.Minute / time.Duration(perMinute))
+	if pullLimiter != nil && pullLimiter.Limit() == limit {
+		return
+	}
+	pullLimiter = rate.NewLimiter(limit, 1)
+}
+
+// showPullProgress 是否以 INFO 级别输出镜像拉取进度，默认仅在 DEBUG 级别输出
+var showPullProgress bool
+
+// SetShowPullProgress 设置是否以 INFO 级别输出镜像拉取进度
+func SetShowPullProgress(enabled bool) {
+	showPullProgress = enabled
+}
+
+// logPullProgress 输出镜像拉取进度
+func logPullProgress(format string, args ...interface{}) {
+	if showPullProgress {
+		logger.Info(format, args...)
+	} else {
+		logger.Debug(format, args...)
+	}
+}
+
 // ImageService 镜像服务
 type ImageService struct {
 	clientManager *ClientManager
+	mirrors       [][2]string     // 拉取重写规则：{原前缀, 镜像源前缀}
+	registry      *registryClient // 直接查询 registry manifest 的客户端
+	minImageAge   time.Duration   // 新镜像创建时间需超过该时长才视为有更新
+	pullTimeout   time.Duration   // 单次拉取的超时时间，<=0 表示不限制
+	semverTags    bool            // 版本号 tag 的镜像改为检查是否有更高版本的 tag
+	semverMajor   bool            // 检查更高版本的 tag 时允许跨主版本
+
+	platformOnce sync.Once
+	platform     platform // Docker 主机的平台，首次查询 manifest 时获取
 }
 
 // NewImageService 创建镜像服务实例
 func NewImageService(clientManager *ClientManager) *ImageService {
 	return &ImageService{
 		clientManager: clientManager,
+		registry:      defaultRegistry,
+	}
+}
+
+// SetMirrors 设置镜像拉取重写规则，每条格式为 原前缀=镜像源前缀
+func (is *ImageService) SetMirrors(rules []string) {
+	is.mirrors = nil
+	for _, rule := range rules {
+		if from, to, ok := strings.Cut(rule, "="); ok {
+			is.mirrors = append(is.mirrors, [2]string{from, to})
+		}
 	}
 }
 
+// SetMinImageAge 设置新镜像触发更新所需的最小年龄，<=0 表示不限制
+func (is *ImageService) SetMinImageAge(age time.Duration) {
+	is.minImageAge = age
+}
+
+// SetPullTimeout 设置单次镜像拉取的超时时间，<=0 表示不限制
+func (is *ImageService) SetPullTimeout(timeout time.Duration) {
+	is.pullTimeout = timeout
+}
+
+// SetSemverTags 设置是否对版本号 tag（如 1.25.3）的镜像检查更高版本的 tag，latest 等其他 tag 仍按摘要比对
+func (is *ImageService) SetSemverTags(enabled bool) {
+	is.semverTags = enabled
+}
+
+// SetSemverAllowMajor 设置检查更高版本的 tag 时是否允许跨主版本，默认只在同一主版本内更新
+func (is *ImageService) SetSemverAllowMajor(enabled bool) {
+	is.semverMajor = enabled
+}
+
+// tooNew 判断镜像的创建时间是否还未达到最小镜像年龄
+func (is *ImageService) tooNew(imageName string, created time.Time) bool {
+	if is.minImageAge <= 0 {
+		return false
+	}
+	age := time.Since(created)
+	if age >= is.minImageAge {
+		return false
+	}
+	logger.Info("镜像 %s 的新版本创建于 %v 前，未达到最小镜像年龄 %v，暂不更新", imageName, age.Round(time.Minute), is.minImageAge)
+	return true
+}
+
+// mirrorReference 按重写规则得到实际拉取的引用，前缀需完整匹配到仓库名边界
+func (is *ImageService) mirrorReference(imageName string) string {
+	for _, m := range is.mirrors {
+		from, to := m[0], m[1]
+		if imageName == from {
+			return to
+		}
+		if strings.HasPrefix(imageName, from) {
+			switch imageName[len(from)] {
+			case ':', '@', '/':
+				return to + imageName[len(from):]
+			}
+		}
+	}
+	return imageName
+}
+
+// hostPlatform 返回 Docker 主机的平台，远程主机的平台可能与本进程不同，获取失败时使用本进程的平台
+func (is *ImageService) hostPlatform(ctx context.Context) platform {
+	is.platformOnce.Do(func() {
+		is.platform = platform{os: runtime.GOOS, arch: runtime.GOARCH}
+
+		version, err := is.clientManager.GetClient().ServerVersion(ctx)
+		if err != nil {
+			logger.Debug("获取 Docker 主机平台失败，使用本机平台 %s: %v", is.platform, err)
+			return
+		}
+		if version.Os != "" && version.Arch != "" {
+			is.platform = platform{os: version.Os, arch: version.Arch}
+		}
+		logger.Debug("Docker 主机平台: %s", is.platform)
+	})
+	return is.platform
+}
+
 // getImageList 获取镜像列表的通用方法
 func (is *ImageService) getImageList(ctx context.Context, imageName string) ([]image.Summary, error) {
 	cli := is.clientManager.GetClient()
@@ -67,50 +205,246 @@ func (is *ImageService) NormalizeReference(ctx context.Context, imageName string
 	return imageName, nil
 }
 
-// GetLocalHash 获取本地镜像的哈希值
+// newerTagReference 查询 registry 上与镜像当前 tag 同一系列的更高版本，返回更新使用的镜像引用；
+// tag 不是版本号形式、没有更高版本或查询失败时返回空字符串
+func (is *ImageService) newerTagReference(ctx context.Context, imageName string) string {
+	named, err := reference.ParseNormalizedNamed(imageName)
+	if err != nil {
+		return ""
+	}
+	tagged, ok := named.(reference.Tagged)
+	if !ok {
+		return ""
+	}
+	if _, ok := named.(reference.Digested); ok {
+		return ""
+	}
+	if _, ok := parseVersionTag(tagged.Tag()); !ok {
+		return ""
+	}
+
+	tags, err := is.registry.Tags(ctx, imageName)
+	if err != nil {
+		logger.Debug("无法列出镜像 %s 的 tag，改为按摘要比对: %v", imageName, err)
+		return ""
+	}
+	newest := newestTag(tagged.Tag(), tags, is.semverMajor)
+	if newest == "" {
+		return ""
+	}
+
+	newRef, err := reference.WithTag(reference.TrimNamed(named), newest)
+	if err != nil {
+		return ""
+	}
+	return reference.FamiliarString(newRef)
+}
+
+// checkTagUpdate 拉取更高版本 tag 的镜像，作为当前镜像的更新
+func (is *ImageService) checkTagUpdate(ctx context.Context, result *types.ImageCheckResult, imageName, newRef string) (*types.ImageCheckResult, error) {
+	logger.Info("镜像 %s 有更高版本的 tag: %s", imageName, newRef)
+
+	remoteImage, err := is.pullImage(ctx, newRef)
+	if err != nil {
+		result.Reason = types.ReasonRemoteError
+		result.Error = fmt.Sprintf("拉取镜像 %s 失败: %v", newRef, err)
+		return result, err
+	}
+
+	result.RemoteHash = imageDigest(remoteImage, newRef)
+	result.UpdateRef = newRef
+	result.IsUpdated = true
+
+	// 新版本过新时暂不更新，原 tag 未被改动，下次检查时重新判断
+	if is.tooNew(newRef, time.Unix(remoteImage.Created, 0)) {
+		result.IsUpdated = false
+		result.Reason = types.ReasonTooNew
+	}
+	return result, nil
+}
+
+// GetLocalHash 获取本地镜像的内容摘要
 func (is *ImageService) GetLocalHash(ctx context.Context, imageName string) (string, error) {
+	img, err := is.getLocalImage(ctx, imageName)
+	if err != nil {
+		return "", err
+	}
+
+	return imageDigest(img, imageName), nil
+}
+
+// getLocalImage 获取与引用匹配的本地镜像
+func (is *ImageService) getLocalImage(ctx context.Context, imageName string) (image.Summary, error) {
 	images, err := is.getImageList(ctx, imageName)
 	if err != nil {
-		return "", fmt.Errorf("获取本地镜像列表失败: %w", err)
+		return image.Summary{}, fmt.Errorf("获取本地镜像列表失败: %w", err)
 	}
 
 	if len(images) == 0 {
-		return "", fmt.Errorf("本地不存在镜像: %s", imageName)
+		return image.Summary{}, fmt.Errorf("%w: %s", ErrImageNotFound, imageName)
+	}
+
+	return images[0], nil
+}
+
+// imageDigest 获取镜像在 registry 上的内容摘要，优先使用与引用同仓库的 RepoDigest，
+// 其次使用其他仓库（如镜像源）的 RepoDigest，没有 RepoDigest 的本地构建镜像退回使用镜像ID
+func imageDigest(img image.Summary, imageName string) string {
+	named, _ := reference.ParseNormalizedNamed(imageName)
+
+	fallback := img.ID
+	for i, repoDigest := range img.RepoDigests {
+		canonical, err := reference.ParseNormalizedNamed(repoDigest)
+		if err != nil {
+			continue
+		}
+		digested, ok := canonical.(reference.Digested)
+		if !ok {
+			continue
+		}
+		if named != nil && canonical.Name() == named.Name() {
+			return digested.Digest().String()
+		}
+		if i == 0 {
+			fallback = digested.Digest().String()
+		}
 	}
 
-	// 使用镜像ID作为哈希值
-	return images[0].ID, nil
+	return fallback
 }
 
-// GetRemoteHash 获取远程镜像的哈希值
+// GetRemoteHash 拉取镜像后获取 registry 端的内容摘要
 func (is *ImageService) GetRemoteHash(ctx context.Context, imageName string) (string, error) {
+	img, err := is.pullImage(ctx, imageName)
+	if err != nil {
+		return "", err
+	}
+	return imageDigest(img, imageName), nil
+}
+
+// pullImage 拉取镜像并返回拉取后的本地镜像信息
+func (is *ImageService) pullImage(ctx context.Context, imageName string) (image.Summary, error) {
 	cli := is.clientManager.GetClient()
 
-	// 拉取镜像以获取最新信息
-	reader, err := cli.ImagePull(ctx, imageName, image.PullOptions{})
+	// 拉取镜像以获取最新信息，配置了镜像源时从镜像源拉取
+	pullRef := is.mirrorReference(imageName)
+	if pullRef != imageName {
+		logger.Debug("镜像 %s 通过镜像源 %s 拉取", imageName, pullRef)
+	}
+
+	// 等待拉取令牌，避免触发 registry 的拉取频率限制
+	if pullLimiter != nil {
+		if err := pullLimiter.Wait(ctx); err != nil {
+			return image.Summary{}, fmt.Errorf("等待拉取令牌失败: %w", err)
+		}
+	}
+
+	// 超时只作用于拉取本身，不包含等待拉取令牌的时间
+	pullCtx := ctx
+	if is.pullTimeout > 0 {
+		var cancel context.CancelFunc
+		pullCtx, cancel = context.WithTimeout(ctx, is.pullTimeout)
+		defer cancel()
+	}
+
+	reader, err := cli.ImagePull(pullCtx, pullRef, image.PullOptions{})
 	if err != nil {
-		return "", fmt.Errorf("拉取镜像失败: %w", err)
+		if errdefs.IsNotFound(err) {
+			return image.Summary{}, fmt.Errorf("%w: %s: %v", ErrRemoteNotFound, imageName, err)
+		}
+		if pullTimedOut(ctx, pullCtx) {
+			return image.Summary{}, fmt.Errorf("拉取镜像超时（%v）: %w", is.pullTimeout, err)
+		}
+		return image.Summary{}, fmt.Errorf("拉取镜像失败: %w", err)
 	}
 	defer reader.Close()
 
-	scanner := bufio.NewScanner(reader)
+	// 汇总输出拉取进度
+	if err := consumePullStream(imageName, reader); err != nil {
+		if pullTimedOut(ctx, pullCtx) {
+			return image.Summary{}, fmt.Errorf("拉取镜像超时（%v）: %w", is.pullTimeout, err)
+		}
+		return image.Summary{}, err
+	}
 
-	for scanner.Scan() {
-		// 输出拉取镜像日志
-		logger.Debug("%s", scanner.Text())
+	// 将镜像源拉取的镜像重新标记为原引用，容器重建时才能使用新镜像
+	if pullRef != imageName {
+		if err := cli.ImageTag(ctx, pullRef, imageName); err != nil {
+			return image.Summary{}, fmt.Errorf("标记镜像 %s 为 %s 失败: %w", pullRef, imageName, err)
+		}
 	}
 
 	// 重新获取镜像信息以获取最新的哈希值
 	images, err := is.getImageList(ctx, imageName)
 	if err != nil {
-		return "", fmt.Errorf("获取更新后的镜像信息失败: %w", err)
+		return image.Summary{}, fmt.Errorf("获取更新后的镜像信息失败: %w", err)
 	}
 
 	if len(images) == 0 {
-		return "", fmt.Errorf("拉取后未找到镜像: %s", imageName)
+		return image.Summary{}, fmt.Errorf("拉取后仍未找到镜像 %s: %w", imageName, ErrImageNotFound)
+	}
+
+	return images[0], nil
+}
+
+// pullTimedOut 判断拉取是否因单次拉取超时而中止，而不是整体检查被取消或超时
+func pullTimedOut(ctx, pullCtx context.Context) bool {
+	return ctx.Err() == nil && errors.Is(pullCtx.Err(), context.DeadlineExceeded)
+}
+
+// consumePullStream 解析镜像拉取的 JSON 输出流，仅在层完成和整体完成时汇总输出进度
+func consumePullStream(imageName string, reader io.Reader) error {
+	decoder := json.NewDecoder(reader)
+	layers := make(map[string]bool) // 层ID -> 是否已完成
+	completed := 0
+
+	for {
+		var msg jsonmessage.JSONMessage
+		if err := decoder.Decode(&msg); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return fmt.Errorf("读取镜像拉取输出失败: %w", err)
+		}
+
+		if msg.Error != nil {
+			if isNotFoundMessage(msg.Error.Message) {
+				return fmt.Errorf("%w: %s: %s", ErrRemoteNotFound, imageName, msg.Error.Message)
+			}
+			return fmt.Errorf("拉取镜像失败: %s", msg.Error.Message)
+		}
+
+		// 不带层ID的消息为整体状态，例如 "Status: Image is up to date for ..."
+		if msg.ID == "" || strings.HasPrefix(msg.Status, "Pulling from") {
+			if strings.HasPrefix(msg.Status, "Status:") || strings.HasPrefix(msg.Status, "Digest:") {
+				logPullProgress("镜像 %s %s", imageName, msg.Status)
+			}
+			continue
+		}
+
+		done, seen := layers[msg.ID]
+		if !seen {
+			layers[msg.ID] = false
+		}
+		if done {
+			continue
+		}
+
+		switch msg.Status {
+		case "Pull complete", "Already exists":
+			layers[msg.ID] = true
+			completed++
+			logPullProgress("镜像 %s 拉取进度: %d/%d 层 (%d%%)", imageName, completed, len(layers), completed*100/len(layers))
+		}
 	}
+}
 
-	return images[0].ID, nil
+// isNotFoundMessage 判断拉取错误信息是否表示 registry 上不存在该镜像
+func isNotFoundMessage(message string) bool {
+	message = strings.ToLower(message)
+	return strings.Contains(message, "not found") ||
+		strings.Contains(message, "manifest unknown") ||
+		strings.Contains(message, "repository does not exist")
 }
 
 // CheckUpdate 检查镜像是否有更新
@@ -120,24 +454,142 @@ func (is *ImageService) CheckUpdate(ctx context.Context, imageName string) (*typ
 		CheckedAt: time.Now(),
 	}
 
-	// 获取本地镜像哈希
-	localHash, err := is.GetLocalHash(ctx, imageName)
-	if err != nil {
+	// 获取本地镜像哈希，本地缺失时后续拉取结果作为基线
+	localImage, err := is.getLocalImage(ctx, imageName)
+	localMissing := errors.Is(err, ErrImageNotFound)
+	if err != nil && !localMissing {
+		result.Reason = types.ReasonLocalError
 		result.Error = fmt.Sprintf("获取本地镜像信息失败: %v", err)
 		return result, err
 	}
+
+	var localHash string
+	if !localMissing {
+		// 没有任何 RepoDigest 说明镜像从未从 registry 拉取过，是本地构建的镜像
+		if len(localImage.RepoDigests) == 0 {
+			logger.Info("镜像 %s 为本地构建镜像，跳过检查", imageName)
+			result.LocalHash = localImage.ID
+			result.RemoteHash = localImage.ID
+			result.Reason = types.ReasonLocalOnly
+			return result, nil
+		}
+		localHash = imageDigest(localImage, imageName)
+	}
 	result.LocalHash = localHash
 
-	// 获取远程镜像哈希
-	remoteHash, err := is.GetRemoteHash(ctx, imageName)
+	// 版本号 tag 优先检查是否有更高版本，没有时再按摘要检查同一 tag 是否被重新发布
+	if is.semverTags && !localMissing {
+		if newRef := is.newerTagReference(ctx, imageName); newRef != "" {
+			return is.checkTagUpdate(ctx, result, imageName, newRef)
+		}
+	}
+
+	// 先通过轻量的 manifest HEAD 请求确认 registry 上的镜像是否变化，未变化时无需拉取
+	var manifest manifestDigests
+	manifest.digest, err = is.GetManifestDigest(ctx, imageName)
 	if err != nil {
-		result.Error = fmt.Sprintf("获取远程镜像信息失败: %v", err)
+		logger.Debug("无法获取镜像 %s 的 manifest 摘要，改为拉取比对: %v", imageName, err)
+	} else if !localMissing {
+		if manifest.digest == localHash {
+			result.RemoteHash = localHash
+			return result, nil
+		}
+		if cached, ok := cachedRemoteHash(imageName, manifest.digest); ok && cached == localHash {
+			logger.Debug("镜像 %s 的 manifest 摘要未变化，跳过拉取", imageName)
+			result.RemoteHash = localHash
+			return result, nil
+		}
+		// 摘要已变化时才读取清单，多架构清单因其他平台更新而变化时，本机平台的 manifest 不变，无需拉取
+		manifest.platform = is.getPlatformDigest(ctx, imageName)
+		if platformUnchanged(imageName, manifest.platform, localHash) {
+			logger.Debug("镜像 %s 的多架构清单摘要已变化，但本机平台的镜像未变化，跳过拉取", imageName)
+			storeDigest(imageName, manifest, localHash)
+			result.RemoteHash = localHash
+			return result, nil
+		}
+	}
+
+	// 拉取镜像获取远程镜像哈希
+	remoteImage, err := is.pullImage(ctx, imageName)
+	if err != nil {
+		if errors.Is(err, ErrRemoteNotFound) && !localMissing {
+			logger.Info("镜像 %s 在 registry 上不存在，视为本地镜像跳过检查", imageName)
+			result.RemoteHash = localHash
+			result.Reason = types.ReasonLocalOnly
+			return result, nil
+		}
+		if errors.Is(err, ErrImageNotFound) {
+			result.Reason = types.ReasonReferenceMismatch
+			result.Error = fmt.Sprintf("镜像引用 %s 拉取后无法匹配本地镜像，请检查引用格式（registry 前缀、tag 等）: %v", imageName, err)
+		} else {
+			result.Reason = types.ReasonRemoteError
+			result.Error = fmt.Sprintf("获取远程镜像信息失败: %v", err)
+		}
 		return result, err
 	}
+	remoteHash := imageDigest(remoteImage, imageName)
 	result.RemoteHash = remoteHash
+	if manifest.digest != "" {
+		storeDigest(imageName, manifest, remoteHash)
+	}
+
+	if localMissing {
+		logger.Info("本地不存在镜像 %s，已拉取作为比对基线", imageName)
+		result.LocalHash = remoteHash
+		result.Reason = types.ReasonLocalPulled
+	} else if remoteImage.ID == localImage.ID && remoteHash != localHash {
+		// 多架构镜像其他平台更新时清单摘要会变化，但本机平台的镜像未变，以镜像ID为准
+		logger.Debug("镜像 %s 的多架构清单摘要已变化，但本机平台的镜像未变化", imageName)
+		result.LocalHash = remoteHash
+	}
 
-	// 比较哈希值判断是否有更新
-	result.IsUpdated = localHash != remoteHash
+	// 比较哈希值判断是否有更新，本地缺失时刚拉取的镜像即为基线，不视为有更新
+	result.IsUpdated = result.LocalHash != remoteHash
+
+	// 新镜像过新时暂不更新，并将标签恢复到旧镜像，下次检查时重新判断
+	if result.IsUpdated && is.tooNew(imageName, time.Unix(remoteImage.Created, 0)) {
+		if err := is.clientManager.GetClient().ImageTag(ctx, localImage.ID, imageName); err != nil {
+			logger.Warn("恢复镜像 %s 的标签失败，新版本将不会再被检测为更新: %v", imageName, err)
+		}
+		result.IsUpdated = false
+		result.Reason = types.ReasonTooNew
+	}
+
+	return result, nil
+}
+
+// CheckLocalUpdate 不访问 registry，比对容器当前使用的镜像ID与本地同名镜像的ID，
+// 不一致说明本地已有更新的镜像（例如已手动拉取），可直接用于重建容器
+func (is *ImageService) CheckLocalUpdate(ctx context.Context, imageName string, containerImageIDs []string) (*types.ImageCheckResult, error) {
+	result := &types.ImageCheckResult{
+		Name:      imageName,
+		CheckedAt: time.Now(),
+	}
+
+	cli := is.clientManager.GetClient()
+	inspect, _, err := cli.ImageInspectWithRaw(ctx, imageName)
+	if err != nil {
+		result.Reason = types.ReasonLocalError
+		result.Error = fmt.Sprintf("获取本地镜像信息失败: %v", err)
+		return result, err
+	}
+
+	result.LocalHash = inspect.ID
+	result.RemoteHash = inspect.ID
+	for _, id := range containerImageIDs {
+		if id != inspect.ID {
+			result.LocalHash = id
+			result.IsUpdated = true
+			break
+		}
+	}
+
+	if result.IsUpdated {
+		if created, err := time.Parse(time.RFC3339Nano, inspect.Created); err == nil && is.tooNew(imageName, created) {
+			result.IsUpdated = false
+			result.Reason = types.ReasonTooNew
+		}
+	}
 
 	return result, nil
 }
@@ -156,3 +608,58 @@ func (is *ImageService) CleanDanglingImages(ctx context.Context) error {
 
 	return nil
 }
+
+// RemoveImage 删除指定镜像，镜像仍被容器使用或带有其他标签时由 Docker 拒绝删除
+func (is *ImageService) RemoveImage(ctx context.Context, imageID string) error {
+	cli := is.clientManager.GetClient()
+
+	if _, err := cli.ImageRemove(ctx, imageID, image.RemoveOptions{PruneChildren: true}); err != nil {
+		return fmt.Errorf("删除镜像 %s 失败: %w", imageID, err)
+	}
+	return nil
+}
+
+// CommitBackup 将容器提交为备份镜像 repo:backup-时间戳，返回备份镜像引用
+func (is *ImageService) CommitBackup(ctx context.Context, containerID, repo string) (string, error) {
+	cli := is.clientManager.GetClient()
+
+	ref := fmt.Sprintf("%s:backup-%s", strings.ToLower(repo), time.Now().Format("20060102-150405"))
+	if _, err := cli.ContainerCommit(ctx, containerID, container.CommitOptions{
+		Reference: ref,
+		Comment:   "watchducker backup",
+	}); err != nil {
+		return "", fmt.Errorf("提交备份镜像 %s 失败: %w", ref, err)
+	}
+
+	return ref, nil
+}
+
+// PruneBackups 只保留 repo 最近的 keep 个备份镜像
+func (is *ImageService) PruneBackups(ctx context.Context, repo string, keep int) error {
+	cli := is.clientManager.GetClient()
+
+	images, err := is.getImageList(ctx, strings.ToLower(repo)+":backup-*")
+	if err != nil {
+		return fmt.Errorf("获取备份镜像列表失败: %w", err)
+	}
+
+	if len(images) <= keep {
+		return nil
+	}
+
+	// 按创建时间从新到旧排序
+	sort.Slice(images, func(i, j int) bool {
+		return images[i].Created > images[j].Created
+	})
+
+	for _, img := range images[keep:] {
+		for _, tag := range img.RepoTags {
+			if _, err := cli.ImageRemove(ctx, tag, image.RemoveOptions{PruneChildren: true}); err != nil {
+				return fmt.Errorf("删除备份镜像 %s 失败: %w", tag, err)
+			}
+			logger.Debug("已删除过期备份镜像: %s", tag)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,421 @@
+package docker
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"watchducker/pkg/logger"
+
+	"github.com/distribution/reference"
+)
+
+// manifestMediaTypes 查询 manifest 时接受的类型，多架构镜像优先返回清单本身，其摘要与拉取后记录的 RepoDigest 一致
+var manifestMediaTypes = []string{
+	"application/vnd.oci.image.index.v1+json",
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+	"application/vnd.docker.distribution.manifest.v2+json",
+	"application/vnd.oci.image.manifest.v1+json",
+}
+
+// manifestListMediaTypes 多架构清单的类型，需按平台选出实际使用的 manifest
+var manifestListMediaTypes = []string{
+	"application/vnd.oci.image.index.v1+json",
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+}
+
+// registryTimeout 单次 registry 请求的超时时间
+const registryTimeout = 30 * time.Second
+
+// maxManifestSize 读取 manifest 内容的大小上限
+const maxManifestSize = 4 << 20
+
+// platform 镜像平台，用于从多架构清单中选出本机实际拉取的 manifest
+type platform struct {
+	os      string
+	arch    string
+	variant string
+}
+
+// String 返回 os/arch[/variant] 形式的平台描述
+func (p platform) String() string {
+	if p.variant == "" {
+		return p.os + "/" + p.arch
+	}
+	return p.os + "/" + p.arch + "/" + p.variant
+}
+
+// registryToken 缓存的 bearer token
+type registryToken struct {
+	value     string
+	expiresAt time.Time
+}
+
+// registryClient 直接访问 registry HTTP API 的客户端，按 WWW-Authenticate 的指示匿名获取 bearer token，
+// 用于在不拉取镜像的情况下查询 manifest 摘要
+type registryClient struct {
+	httpClient *http.Client
+
+	mu     sync.Mutex
+	tokens map[string]registryToken // 键为 realm、service 和 scope 的组合
+}
+
+// defaultRegistry 所有主机和检查共享的 registry 客户端，复用已获取的 token
+var defaultRegistry = &registryClient{
+	httpClient: &http.Client{Timeout: registryTimeout},
+	tokens:     make(map[string]registryToken),
+}
+
+// manifestDigests 镜像在 registry 上的 manifest 摘要
+type manifestDigests struct {
+	digest   string // 引用指向的 manifest 摘要，多架构镜像为清单摘要，与拉取后的 RepoDigest 一致
+	platform string // 与 Docker 主机平台对应的 manifest 摘要，只在清单摘要变化时查询，未查询或无法确定时为空
+}
+
+// manifestURL 返回镜像引用的 manifest 地址、所在 registry 主机和拉取权限的 scope
+func manifestURL(imageRef string) (string, string, string, error) {
+	named, err := reference.ParseNormalizedNamed(imageRef)
+	if err != nil {
+		return "", "", "", fmt.Errorf("解析镜像引用 %s 失败: %w", imageRef, err)
+	}
+	named = reference.TagNameOnly(named)
+	host, repository := registryEndpoint(named)
+
+	var ref string
+	if digested, ok := named.(reference.Digested); ok {
+		ref = digested.Digest().String()
+	} else if tagged, ok := named.(reference.Tagged); ok {
+		ref = tagged.Tag()
+	}
+
+	return fmt.Sprintf("https://%s/v2/%s/manifests/%s", host, repository, ref), host, fmt.Sprintf("repository:%s:pull", repository), nil
+}
+
+// ManifestDigest 查询镜像引用在 registry 上的 manifest 摘要，registry 返回 404 时返回 ErrRemoteNotFound。
+// 多架构镜像返回清单摘要，只发送 HEAD 请求；registry 未返回 Docker-Content-Digest 时才读取内容计算摘要
+func (rc *registryClient) ManifestDigest(ctx context.Context, imageRef string) (string, error) {
+	addr, host, scope, err := manifestURL(imageRef)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := rc.request(ctx, http.MethodHead, addr, scope, manifestMediaTypes)
+	if err != nil {
+		return "", err
+	}
+	resp.Body.Close()
+
+	if digest := resp.Header.Get("Docker-Content-Digest"); digest != "" {
+		return digest, nil
+	}
+
+	logger.Debug("registry %s 未返回 Docker-Content-Digest，按 manifest 内容计算摘要", host)
+	digest, _, _, err := rc.fetchManifest(ctx, addr, scope)
+	return digest, err
+}
+
+// PlatformDigest 查询镜像引用在 registry 上与 p 匹配的 manifest 摘要，用于识别多架构清单中只有其他平台更新的情况。
+// 需要读取清单内容，Docker Hub 会将其计入拉取次数，只应在清单摘要变化时调用
+func (rc *registryClient) PlatformDigest(ctx context.Context, imageRef string, p platform) (string, error) {
+	addr, _, scope, err := manifestURL(imageRef)
+	if err != nil {
+		return "", err
+	}
+
+	digest, body, contentType, err := rc.fetchManifest(ctx, addr, scope)
+	if err != nil {
+		return "", err
+	}
+	if !isManifestList(contentType) {
+		return digest, nil
+	}
+	return selectPlatformDigest(body, p)
+}
+
+// fetchManifest 读取 manifest 内容，返回其摘要、内容和类型
+func (rc *registryClient) fetchManifest(ctx context.Context, addr, scope string) (string, []byte, string, error) {
+	resp, err := rc.request(ctx, http.MethodGet, addr, scope, manifestMediaTypes)
+	if err != nil {
+		return "", nil, "", err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxManifestSize))
+	if err != nil {
+		return "", nil, "", fmt.Errorf("读取 manifest 失败: %w", err)
+	}
+	digest := resp.Header.Get("Docker-Content-Digest")
+	if digest == "" {
+		digest = fmt.Sprintf("sha256:%x", sha256.Sum256(body))
+	}
+	return digest, body, resp.Header.Get("Content-Type"), nil
+}
+
+// registryEndpoint 返回镜像所在 registry 的 API 主机和仓库路径，Docker Hub 的 API 主机与镜像名中的域名不同
+func registryEndpoint(named reference.Named) (string, string) {
+	host := reference.Domain(named)
+	if host == "docker.io" {
+		host = "registry-1.docker.io"
+	}
+	return host, reference.Path(named)
+}
+
+// maxTagPages 列出 tag 时最多跟随的分页数，避免 tag 极多的仓库耗时过长
+const maxTagPages = 20
+
+// Tags 列出镜像仓库在 registry 上的所有 tag，按 Link 头跟随分页
+func (rc *registryClient) Tags(ctx context.Context, imageRef string) ([]string, error) {
+	named, err := reference.ParseNormalizedNamed(imageRef)
+	if err != nil {
+		return nil, fmt.Errorf("解析镜像引用 %s 失败: %w", imageRef, err)
+	}
+	host, repository := registryEndpoint(named)
+	scope := fmt.Sprintf("repository:%s:pull", repository)
+
+	var tags []string
+	next := fmt.Sprintf("https://%s/v2/%s/tags/list?n=1000", host, repository)
+	for page := 0; page < maxTagPages && next != ""; page++ {
+		resp, err := rc.request(ctx, http.MethodGet, next, scope, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		var payload struct {
+			Tags []string `json:"tags"`
+		}
+		err = json.NewDecoder(io.LimitReader(resp.Body, maxManifestSize)).Decode(&payload)
+		link := resp.Header.Get("Link")
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("解析 tag 列表失败: %w", err)
+		}
+		tags = append(tags, payload.Tags...)
+
+		next = nextPageURL(host, link)
+	}
+	return tags, nil
+}
+
+// nextPageURL 解析 Link 头中 rel="next" 的分页地址，如 </v2/library/nginx/tags/list?last=1.25&n=1000>; rel="next"
+func nextPageURL(host, link string) string {
+	target, params, ok := strings.Cut(link, ";")
+	if !ok || !strings.Contains(params, `rel="next"`) {
+		return ""
+	}
+	target = strings.Trim(strings.TrimSpace(target), "<>")
+	if strings.HasPrefix(target, "/") {
+		return "https://" + host + target
+	}
+	return target
+}
+
+// isManifestList 判断 Content-Type 是否为多架构清单
+func isManifestList(contentType string) bool {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	mediaType = strings.TrimSpace(mediaType)
+	for _, t := range manifestListMediaTypes {
+		if mediaType == t {
+			return true
+		}
+	}
+	return false
+}
+
+// selectPlatformDigest 从多架构清单中选出与 p 匹配的 manifest 摘要，变体一致的优先
+func selectPlatformDigest(body []byte, p platform) (string, error) {
+	var index struct {
+		Manifests []struct {
+			Digest   string `json:"digest"`
+			Platform *struct {
+				OS           string `json:"os"`
+				Architecture string `json:"architecture"`
+				Variant      string `json:"variant"`
+			} `json:"platform"`
+		} `json:"manifests"`
+	}
+	if err := json.Unmarshal(body, &index); err != nil {
+		return "", fmt.Errorf("解析多架构清单失败: %w", err)
+	}
+
+	var candidate string
+	for _, m := range index.Manifests {
+		if m.Platform == nil || m.Platform.OS != p.os || m.Platform.Architecture != p.arch {
+			continue
+		}
+		if m.Platform.Variant == p.variant {
+			return m.Digest, nil
+		}
+		if candidate == "" && (p.variant == "" || m.Platform.Variant == "") {
+			candidate = m.Digest
+		}
+	}
+	if candidate == "" {
+		return "", fmt.Errorf("多架构清单中没有 %s 平台的镜像", p)
+	}
+	return candidate, nil
+}
+
+// request 请求 registry API，收到 401 时按 WWW-Authenticate 获取 token 后重试一次，返回状态码为 200 的响应
+func (rc *registryClient) request(ctx context.Context, method, requestURL, scope string, accept []string) (*http.Response, error) {
+	var token string
+	for attempt := 0; attempt < 2; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, method, requestURL, nil)
+		if err != nil {
+			return nil, err
+		}
+		if len(accept) > 0 {
+			req.Header.Set("Accept", strings.Join(accept, ", "))
+		}
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+
+		resp, err := rc.httpClient.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("请求 registry 失败: %w", err)
+		}
+
+		switch resp.StatusCode {
+		case http.StatusOK:
+			return resp, nil
+		case http.StatusNotFound:
+			resp.Body.Close()
+			return nil, fmt.Errorf("%w: %s", ErrRemoteNotFound, requestURL)
+		case http.StatusUnauthorized:
+			challenge := resp.Header.Get("WWW-Authenticate")
+			resp.Body.Close()
+			if token != "" {
+				return nil, fmt.Errorf("registry 拒绝了获取到的 token（状态码 %d）", resp.StatusCode)
+			}
+			token, err = rc.token(ctx, challenge, scope)
+			if err != nil {
+				return nil, err
+			}
+		default:
+			resp.Body.Close()
+			return nil, fmt.Errorf("请求 registry 返回状态码 %d", resp.StatusCode)
+		}
+	}
+
+	return nil, fmt.Errorf("请求 registry 鉴权失败")
+}
+
+// token 按 WWW-Authenticate 中的 Bearer 挑战匿名获取 token，有效期内复用缓存
+func (rc *registryClient) token(ctx context.Context, challenge, scope string) (string, error) {
+	scheme, params := parseChallenge(challenge)
+	if !strings.EqualFold(scheme, "bearer") {
+		return "", fmt.Errorf("registry 需要 %s 鉴权，不支持匿名查询", scheme)
+	}
+
+	realm := params["realm"]
+	if realm == "" {
+		return "", fmt.Errorf("WWW-Authenticate 缺少 realm: %s", challenge)
+	}
+	if params["scope"] != "" {
+		scope = params["scope"]
+	}
+
+	key := realm + "|" + params["service"] + "|" + scope
+	rc.mu.Lock()
+	cached, ok := rc.tokens[key]
+	rc.mu.Unlock()
+	if ok && time.Now().Before(cached.expiresAt) {
+		return cached.value, nil
+	}
+
+	tokenURL, err := url.Parse(realm)
+	if err != nil {
+		return "", fmt.Errorf("无效的 token 地址 %s: %w", realm, err)
+	}
+	query := tokenURL.Query()
+	if service := params["service"]; service != "" {
+		query.Set("service", service)
+	}
+	query.Set("scope", scope)
+	tokenURL.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, tokenURL.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := rc.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("获取 registry token 失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("获取 registry token 返回状态码 %d", resp.StatusCode)
+	}
+
+	var payload struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+		return "", fmt.Errorf("解析 registry token 失败: %w", err)
+	}
+
+	value := payload.Token
+	if value == "" {
+		value = payload.AccessToken
+	}
+	if value == "" {
+		return "", fmt.Errorf("registry token 响应中没有 token")
+	}
+
+	// 未声明有效期时按规范默认 60 秒，提前 10 秒过期避免临界时失效
+	expiresIn := payload.ExpiresIn
+	if expiresIn <= 0 {
+		expiresIn = 60
+	}
+	rc.mu.Lock()
+	rc.tokens[key] = registryToken{
+		value:     value,
+		expiresAt: time.Now().Add(time.Duration(expiresIn)*time.Second - 10*time.Second),
+	}
+	rc.mu.Unlock()
+
+	return value, nil
+}
+
+// parseChallenge 解析 WWW-Authenticate 头，返回鉴权方式和参数，如
+// Bearer realm="https://auth.docker.io/token",service="registry.docker.io",scope="repository:library/nginx:pull"
+func parseChallenge(header string) (string, map[string]string) {
+	params := make(map[string]string)
+	scheme, rest, _ := strings.Cut(strings.TrimSpace(header), " ")
+
+	for rest = strings.TrimSpace(rest); rest != ""; rest = strings.TrimSpace(rest) {
+		key, value, ok := strings.Cut(rest, "=")
+		if !ok {
+			break
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		// 带引号的值中可能包含逗号（如 scope 含多个操作），需找到对应的结束引号
+		if strings.HasPrefix(value, `"`) {
+			end := strings.Index(value[1:], `"`)
+			if end < 0 {
+				params[key] = value[1:]
+				break
+			}
+			params[key] = value[1 : end+1]
+			rest = strings.TrimPrefix(strings.TrimSpace(value[end+2:]), ",")
+			continue
+		}
+
+		value, rest, _ = strings.Cut(value, ",")
+		params[key] = strings.TrimSpace(value)
+	}
+
+	return scheme, params
+}
@@ -0,0 +1,75 @@
+package docker
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestManifestDigestMultiArch(t *testing.T) {
+	const (
+		listDigest  = "sha256:list"
+		amd64Digest = "sha256:amd64"
+		arm64Digest = "sha256:arm64"
+	)
+	index := `{"manifests":[` +
+		`{"digest":"` + amd64Digest + `","platform":{"os":"linux","architecture":"amd64"}},` +
+		`{"digest":"` + arm64Digest + `","platform":{"os":"linux","architecture":"arm64","variant":"v8"}}]}`
+
+	var gets atomic.Int32
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v2/library/app/manifests/latest" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/vnd.oci.image.index.v1+json")
+		w.Header().Set("Docker-Content-Digest", listDigest)
+		if r.Method == http.MethodGet {
+			gets.Add(1)
+			w.Write([]byte(index))
+		}
+	}))
+	defer srv.Close()
+
+	rc := &registryClient{httpClient: srv.Client(), tokens: make(map[string]registryToken)}
+	ref := strings.TrimPrefix(srv.URL, "https://") + "/library/app:latest"
+
+	// 清单摘要只需 HEAD 请求，不能读取清单内容，Docker Hub 会将 manifest GET 计入拉取次数
+	digest, err := rc.ManifestDigest(context.Background(), ref)
+	if err != nil {
+		t.Fatalf("ManifestDigest 返回错误: %v", err)
+	}
+	if digest != listDigest {
+		t.Errorf("摘要 = %q, want %q", digest, listDigest)
+	}
+	if n := gets.Load(); n != 0 {
+		t.Errorf("ManifestDigest 发送了 %d 次 GET 请求，want 0", n)
+	}
+
+	tests := []struct {
+		platform platform
+		want     string
+	}{
+		{platform: platform{os: "linux", arch: "amd64"}, want: amd64Digest},
+		{platform: platform{os: "linux", arch: "arm64", variant: "v8"}, want: arm64Digest},
+		{platform: platform{os: "linux", arch: "arm64"}, want: arm64Digest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.platform.String(), func(t *testing.T) {
+			got, err := rc.PlatformDigest(context.Background(), ref, tt.platform)
+			if err != nil {
+				t.Fatalf("PlatformDigest 返回错误: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("平台摘要 = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := rc.PlatformDigest(context.Background(), ref, platform{os: "windows", arch: "amd64"}); err == nil {
+		t.Error("清单中没有对应平台时应返回错误")
+	}
+}
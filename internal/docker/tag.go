@@ -0,0 +1,77 @@
+package docker
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// versionTagPattern 版本号形式的 tag：可选的 v 前缀、1 到 4 段数字版本号和可选的 -后缀（如 v1.25.3、8.0-alpine）
+var versionTagPattern = regexp.MustCompile(`^(v?)(\d+(?:\.\d+){0,3})(-[0-9A-Za-z][0-9A-Za-z.-]*)?$`)
+
+// versionTag 解析后的版本号 tag，只有前缀、段数和后缀都相同的 tag 才能互相比较
+type versionTag struct {
+	prefix  string
+	numbers []int
+	suffix  string
+}
+
+// parseVersionTag 解析版本号形式的 tag，latest、stable 等非版本号 tag 返回 false
+func parseVersionTag(tag string) (versionTag, bool) {
+	m := versionTagPattern.FindStringSubmatch(tag)
+	if m == nil {
+		return versionTag{}, false
+	}
+
+	parts := strings.Split(m[2], ".")
+	numbers := make([]int, len(parts))
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return versionTag{}, false
+		}
+		numbers[i] = n
+	}
+	return versionTag{prefix: m[1], numbers: numbers, suffix: m[3]}, true
+}
+
+// sameFamily 判断两个版本号 tag 是否属于同一系列（如 1.25.3-alpine 与 1.26.0-alpine），不同系列之间不做比较
+func (v versionTag) sameFamily(other versionTag) bool {
+	return v.prefix == other.prefix && v.suffix == other.suffix && len(v.numbers) == len(other.numbers)
+}
+
+// sameMajor 判断两个同系列的版本号主版本号（第一段数字）是否相同
+func (v versionTag) sameMajor(other versionTag) bool {
+	return v.numbers[0] == other.numbers[0]
+}
+
+// newerThan 判断版本号是否高于 other，调用方需先确认两者属于同一系列
+func (v versionTag) newerThan(other versionTag) bool {
+	for i := range v.numbers {
+		if v.numbers[i] != other.numbers[i] {
+			return v.numbers[i] > other.numbers[i]
+		}
+	}
+	return false
+}
+
+// newestTag 从 tags 中找出与 current 同一系列且版本最高的 tag，没有更高版本时返回空字符串。
+// 主版本升级（如 postgres 15 到 17）常有不兼容变更，allowMajor 为 false 时只在同一主版本内查找
+func newestTag(current string, tags []string, allowMajor bool) string {
+	base, ok := parseVersionTag(current)
+	if !ok {
+		return ""
+	}
+
+	var newest string
+	best := base
+	for _, tag := range tags {
+		v, ok := parseVersionTag(tag)
+		if !ok || !v.sameFamily(base) || (!allowMajor && !v.sameMajor(base)) || !v.newerThan(best) {
+			continue
+		}
+		best = v
+		newest = tag
+	}
+	return newest
+}
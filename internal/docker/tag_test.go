@@ -0,0 +1,31 @@
+package docker
+
+import "testing"
+
+func TestNewestTag(t *testing.T) {
+	tests := []struct {
+		name       string
+		current    string
+		tags       []string
+		allowMajor bool
+		want       string
+	}{
+		{name: "同一主版本内更新", current: "1.25.3", tags: []string{"1.25.3", "1.25.4", "1.26.0", "2.0.0"}, want: "1.26.0"},
+		{name: "只有主版本号时不跨主版本", current: "15", tags: []string{"15", "16", "17"}, want: ""},
+		{name: "带后缀时不跨主版本", current: "18-alpine", tags: []string{"18-alpine", "20-alpine", "22-alpine"}, want: ""},
+		{name: "两段版本号不跨主版本", current: "1.25", tags: []string{"1.26", "2.0"}, want: "1.26"},
+		{name: "显式允许跨主版本", current: "15", tags: []string{"15", "16", "17"}, allowMajor: true, want: "17"},
+		{name: "允许跨主版本时仍区分系列", current: "18-alpine", tags: []string{"22-alpine", "23", "22-slim"}, allowMajor: true, want: "22-alpine"},
+		{name: "前缀不同不比较", current: "v1.2.3", tags: []string{"1.2.4", "v1.2.5"}, want: "v1.2.5"},
+		{name: "非版本号 tag", current: "latest", tags: []string{"1.0.0"}, want: ""},
+		{name: "没有更高版本", current: "1.25.3", tags: []string{"1.25.2", "1.24.9"}, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := newestTag(tt.current, tt.tags, tt.allowMajor); got != tt.want {
+				t.Errorf("newestTag(%q, %v, %v) = %q, want %q", tt.current, tt.tags, tt.allowMajor, got, tt.want)
+			}
+		})
+	}
+}
@@ -4,11 +4,16 @@ import "time"
 
 // ContainerInfo 容器信息
 type ContainerInfo struct {
-	ID     string            `json:"id"`
-	Name   string            `json:"name"`
-	Image  string            `json:"image"`
-	Labels map[string]string `json:"labels"`
-	State  string            `json:"state"`
+	ID      string            `json:"id"`
+	Name    string            `json:"name"`
+	Image   string            `json:"image"`
+	ImageID string            `json:"image_id"` // 容器当前使用的镜像ID
+	Labels  map[string]string `json:"labels"`
+	State   string            `json:"state"`            // 容器状态（running/paused/exited 等）
+	Health  string            `json:"health,omitempty"` // 健康检查状态（healthy/unhealthy/starting），未配置健康检查或未检查时为空
+
+	Created   time.Time `json:"created"`             // 容器创建时间
+	StartedAt time.Time `json:"started_at,omitzero"` // 容器最近一次启动的时间，仅在按运行时长过滤时读取
 }
 
 // ImageCheckResult 镜像检查结果
@@ -19,24 +24,63 @@ type ImageCheckResult struct {
 	IsUpdated  bool      `json:"is_updated"`
 	CheckedAt  time.Time `json:"checked_at"`
 	Error      string    `json:"error,omitempty"`
+	Reason     string    `json:"reason,omitempty"`     // 结果原因，见 Reason* 常量
+	UpdateRef  string    `json:"update_ref,omitempty"` // 有更高版本 tag 时更新使用的镜像引用，为空表示沿用原引用
+
+	SwitchContainers []string `json:"switch_containers,omitempty"` // 镜像本身无更新，但需要切换到 watchducker.target-image 指定的目标镜像的容器ID
 }
 
+// NeedsUpdate 判断是否有需要重建的容器：镜像有更新，或有容器需要切换到目标镜像
+func (r *ImageCheckResult) NeedsUpdate() bool {
+	return r.IsUpdated || len(r.SwitchContainers) > 0
+}
+
+// ContainerUpdateResult 单个容器的更新结果
+type ContainerUpdateResult struct {
+	Name           string `json:"name"`
+	ContainerID    string `json:"container_id"`               // 更新前的容器ID
+	NewContainerID string `json:"new_container_id,omitempty"` // 更新后新建的容器ID
+	Image          string `json:"image"`                      // 镜像引用
+	OldImageID     string `json:"old_image_id"`               // 更新前容器使用的镜像ID
+	NewImageID     string `json:"new_image_id,omitempty"`     // 新镜像ID
+	Success        bool   `json:"success"`
+	Skipped        bool   `json:"skipped,omitempty"` // 容器未稳定运行等原因跳过了更新
+	Error          string `json:"error,omitempty"`
+}
+
+// 镜像检查结果原因
+const (
+	ReasonLocalPulled       = "local_pulled"       // 本地缺失，已拉取作为比对基线
+	ReasonReferenceMismatch = "reference_mismatch" // 镜像引用无法匹配本地镜像
+	ReasonLocalError        = "local_error"        // 读取本地镜像信息失败
+	ReasonRemoteError       = "remote_error"       // 拉取远程镜像失败（网络/registry 问题），更新状态未知
+	ReasonPinned            = "pinned"             // 镜像通过 digest 固定，跳过检查
+	ReasonLocalOnly         = "local-only"         // 本地构建的镜像，registry 上不存在，跳过检查
+	ReasonRegistrySkipped   = "registry_skipped"   // 镜像所在 registry 被配置为忽略，跳过检查
+	ReasonTooNew            = "too_new"            // 新镜像的创建时间未达到最小镜像年龄，暂不更新
+)
+
 // BatchCheckResult 批量检查结果
 type BatchCheckResult struct {
-	Containers []ContainerInfo     `json:"containers"`
-	Images     []*ImageCheckResult `json:"images"`
+	Host       string                  `json:"host,omitempty"`  // 容器所在的 Docker 主机，空表示本地
+	Error      string                  `json:"error,omitempty"` // 主机级别的错误，如无法连接 Docker 主机或创建操作器失败
+	Containers []ContainerInfo         `json:"containers"`
+	Images     []*ImageCheckResult     `json:"images"`
+	Updates    []ContainerUpdateResult `json:"updates,omitempty"` // 各容器的更新结果，未执行更新时为空
 	Summary    struct {
 		TotalContainers int           `json:"total_containers"`
 		TotalImages     int           `json:"total_images"`
 		Updated         int           `json:"updated"`
 		Failed          int           `json:"failed"`
 		UpToDate        int           `json:"up_to_date"`
+		Skipped         int           `json:"skipped"`
+		Unknown         int           `json:"unknown"` // 因网络或 registry 问题无法确认是否有更新
 		Duration        time.Duration `json:"duration"`
 	} `json:"summary"`
 }
 
-// CheckCallback 检查回调函数类型
-type CheckCallback func(*ImageCheckResult)
+// CheckCallback 检查回调函数类型，done/total 为已完成和总共需要检查的镜像数
+type CheckCallback func(info *ImageCheckResult, done, total int)
 
 // CheckMode 检查模式
 type CheckMode int
@@ -2,9 +2,14 @@ package main
 
 import (
 	"context"
+	"os"
+	"os/signal"
+	"sync/atomic"
+	"syscall"
 	"watchducker/cmd"
 	"watchducker/pkg/config"
 	"watchducker/pkg/logger"
+	"watchducker/pkg/notify"
 )
 
 func main() {
@@ -12,12 +17,45 @@ func main() {
 		logger.Fatal("初始化失败: %v", err)
 	}
 
-	ctx := context.Background()
+	if config.Get().TestNotify() {
+		os.Exit(cmd.TestNotify())
+	}
+
+	// 提前校验推送配置，避免运行很久才发现渠道配置有误
+	for _, err := range notify.Validate() {
+		logger.Error("推送配置有误: %v", err)
+	}
+
+	// 收到 Ctrl+C 或 SIGTERM 时取消 ctx，进行中的检查和拉取随之尽快中止
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
 	if config.Get().RunOnce() {
-		cmd.RunOnce(ctx)
-		return
+		exitCode := cmd.RunOnce(ctx)
+		stop()
+		os.Exit(exitCode)
+	}
+
+	if config.Get().WatchEvents() {
+		cmd.RunEventWatcher(ctx)
+	}
+
+	// webhook 服务异常退出时停止守护进程，等待进行中的检查结束后以非零状态码退出
+	var webhookFailed atomic.Bool
+	if config.Get().WebhookListen() != "" {
+		errs := cmd.RunWebhookServer(ctx)
+		go func() {
+			if err := <-errs; err != nil {
+				logger.Error("webhook 服务运行失败，正在退出: %v", err)
+				webhookFailed.Store(true)
+				stop()
+			}
+		}()
 	}
 
 	cmd.RunCronScheduler(ctx)
+	if webhookFailed.Load() {
+		stop()
+		os.Exit(1)
+	}
 }
@@ -1,28 +1,92 @@
 package config
 
 import (
+	"bufio"
 	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
 	"strings"
+	"time"
 
+	"watchducker/pkg/i18n"
 	"watchducker/pkg/logger"
+	"watchducker/pkg/notify"
+	"watchducker/pkg/utils"
 
+	"github.com/robfig/cron/v3"
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 )
 
 // Config 全局配置结构体
 type Config struct {
-	logLevel           string   `mapstructure:"log_level"`
-	containerNames     []string `mapstructure:"-"` // 位置参数，不通过mapstructure绑定
-	checkAll           bool     `mapstructure:"all"`
-	checkLabel         bool     `mapstructure:"label"`
-	checkLabelReversed bool     `mapstructure:"label_reversed"`
-	cronExpression     string   `mapstructure:"cron"`
-	runOnce            bool     `mapstructure:"-"`
-	cleanUp            bool     `mapstructure:"clean_up"`
-	noRestart          bool     `mapstructure:"no_restart"`
-	includeStopped     bool     `mapstructure:"include_stopped"`
-	disabledContainers string   `mapstructure:"disabled_containers"`
+	logLevel               string         `mapstructure:"log_level"`
+	containerNames         []string       `mapstructure:"-"` // 位置参数，不通过mapstructure绑定
+	checkAll               bool           `mapstructure:"all"`
+	checkLabel             bool           `mapstructure:"label"`
+	checkLabelReversed     bool           `mapstructure:"label_reversed"`
+	cronExpression         string         `mapstructure:"cron"`
+	runOnce                bool           `mapstructure:"-"`
+	cronSet                bool           `mapstructure:"-"` // 是否通过命令行或环境变量显式设置了 cron
+	cleanUp                bool           `mapstructure:"clean_up"`
+	noRestart              bool           `mapstructure:"no_restart"`
+	includeStopped         bool           `mapstructure:"include_stopped"`
+	disabledContainers     string         `mapstructure:"disabled_containers"`
+	reportFile             string         `mapstructure:"report_file"`
+	logFile                string         `mapstructure:"log_file"`
+	logFileMaxSize         int            `mapstructure:"log_file_max_size"`
+	logFileMaxBackups      int            `mapstructure:"log_file_max_backups"`
+	logStdout              bool           `mapstructure:"log_stdout"`
+	logFormat              string         `mapstructure:"log_format"`
+	checkTimeout           time.Duration  `mapstructure:"check_timeout"`
+	dockerHost             string         `mapstructure:"docker_host"`
+	backupBeforeUpdate     bool           `mapstructure:"backup_before_update"`
+	backupKeep             int            `mapstructure:"backup_keep"`
+	noColor                bool           `mapstructure:"no_color"`
+	quiet                  bool           `mapstructure:"quiet"`
+	containersFile         string         `mapstructure:"containers_file"`
+	selfUpdate             bool           `mapstructure:"self_update"`
+	selfUpdateGrace        time.Duration  `mapstructure:"self_update_grace"`
+	registryMirror         string         `mapstructure:"registry_mirror"`
+	exitCodeOnUpdate       int            `mapstructure:"exit_code_on_update"`
+	pullRateLimit          int            `mapstructure:"pull_rate_limit"`
+	testNotify             bool           `mapstructure:"test_notify"`
+	notifyDedupWindow      time.Duration  `mapstructure:"notify_dedup_window"`
+	checkConcurrency       int            `mapstructure:"check_concurrency"`
+	updateConcurrency      int            `mapstructure:"update_concurrency"`
+	waitReady              time.Duration  `mapstructure:"wait_ready"`
+	timezone               string         `mapstructure:"timezone"`
+	location               *time.Location `mapstructure:"-"` // 由 timezone 解析得到
+	lang                   string         `mapstructure:"lang"`
+	showPullProgress       bool           `mapstructure:"show_pull_progress"`
+	stateFile              string         `mapstructure:"state_file"`
+	cooldown               time.Duration  `mapstructure:"cooldown"`
+	project                string         `mapstructure:"project"`
+	noPull                 bool           `mapstructure:"no_pull"`
+	cleanupOrphans         bool           `mapstructure:"cleanup_orphans"`
+	inheritEntrypoint      bool           `mapstructure:"inherit_entrypoint"`
+	runTimeout             time.Duration  `mapstructure:"run_timeout"`
+	watchEvents            bool           `mapstructure:"watch_events"`
+	reportMarkdown         string         `mapstructure:"report_markdown"`
+	preRunHook             string         `mapstructure:"pre_run_hook"`
+	postRunHook            string         `mapstructure:"post_run_hook"`
+	skipUnhealthy          bool           `mapstructure:"skip_unhealthy"`
+	skipRegistry           string         `mapstructure:"skip_registry"`
+	webhookListen          string         `mapstructure:"webhook_listen"`
+	webhookSecret          string         `mapstructure:"webhook_secret"`
+	sortBy                 string         `mapstructure:"sort_by"`
+	jitter                 time.Duration  `mapstructure:"jitter"`
+	csvFile                string         `mapstructure:"csv_file"`
+	minImageAge            time.Duration  `mapstructure:"min_image_age"`
+	image                  string         `mapstructure:"image"`
+	pullTimeout            time.Duration  `mapstructure:"pull_timeout"`
+	minUptime              time.Duration  `mapstructure:"min_uptime"`
+	instanceName           string         `mapstructure:"instance_name"`
+	semverTags             bool           `mapstructure:"semver_tags"`
+	concurrencyPerRegistry string         `mapstructure:"concurrency_per_registry"`
+	semverAllowMajor       bool           `mapstructure:"semver_allow_major"`
 }
 
 // 全局配置实例（只读，初始化后不可修改）
@@ -79,7 +143,7 @@ func (c *Config) CronExpression() string {
 	return c.cronExpression
 }
 
-// RunOnce 获取 RunOnce 配置
+// RunOnce 获取 RunOnce 配置，为 true 时只执行一次并忽略 cron 配置
 func (c *Config) RunOnce() bool {
 	return c.runOnce
 }
@@ -104,6 +168,319 @@ func (c *Config) DisabledContainers() []string {
 	return strings.Split(c.disabledContainers, ",")
 }
 
+// ReportFile 获取检查结果报告文件路径
+func (c *Config) ReportFile() string {
+	return c.reportFile
+}
+
+// LogFile 获取日志文件路径
+func (c *Config) LogFile() string {
+	return c.logFile
+}
+
+// LogFileMaxSize 获取单个日志文件的大小上限（MB）
+func (c *Config) LogFileMaxSize() int {
+	return c.logFileMaxSize
+}
+
+// LogFileMaxBackups 获取保留的滚动日志文件数量
+func (c *Config) LogFileMaxBackups() int {
+	return c.logFileMaxBackups
+}
+
+// LogStdout 获取是否同时输出日志到标准输出
+func (c *Config) LogStdout() bool {
+	return c.logStdout
+}
+
+// LogFormat 获取日志输出格式
+func (c *Config) LogFormat() string {
+	return c.logFormat
+}
+
+// CheckTimeout 获取单个镜像检查的超时时间
+func (c *Config) CheckTimeout() time.Duration {
+	return c.checkTimeout
+}
+
+// DockerHosts 获取 Docker 守护进程地址列表，未配置时返回空地址（使用环境变量）
+func (c *Config) DockerHosts() []string {
+	var hosts []string
+	for _, host := range strings.Split(c.dockerHost, ",") {
+		if host = strings.TrimSpace(host); host != "" {
+			hosts = append(hosts, host)
+		}
+	}
+
+	if len(hosts) == 0 {
+		return []string{""}
+	}
+	return hosts
+}
+
+// BackupBeforeUpdate 获取是否在更新前备份容器
+func (c *Config) BackupBeforeUpdate() bool {
+	return c.backupBeforeUpdate
+}
+
+// BackupKeep 获取每个容器保留的备份镜像数量
+func (c *Config) BackupKeep() int {
+	return c.backupKeep
+}
+
+// NoColor 获取是否禁用颜色输出
+func (c *Config) NoColor() bool {
+	return c.noColor
+}
+
+// Quiet 获取是否启用安静模式
+func (c *Config) Quiet() bool {
+	return c.quiet
+}
+
+// ContainersFile 获取容器名称列表文件路径
+func (c *Config) ContainersFile() string {
+	return c.containersFile
+}
+
+// SelfUpdate 获取是否启用自我更新
+func (c *Config) SelfUpdate() bool {
+	return c.selfUpdate
+}
+
+// SelfUpdateGrace 获取自我更新的稳定等待时间
+func (c *Config) SelfUpdateGrace() time.Duration {
+	return c.selfUpdateGrace
+}
+
+// RegistryMirrors 获取镜像拉取重写规则列表，每条格式为 原前缀=镜像源前缀
+func (c *Config) RegistryMirrors() []string {
+	var rules []string
+	for _, rule := range strings.Split(c.registryMirror, ",") {
+		if rule = strings.TrimSpace(rule); rule != "" {
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
+
+// ExitCodeOnUpdate 获取有镜像更新时的退出码
+func (c *Config) ExitCodeOnUpdate() int {
+	return c.exitCodeOnUpdate
+}
+
+// PullRateLimit 获取每分钟最多拉取镜像的次数
+func (c *Config) PullRateLimit() int {
+	return c.pullRateLimit
+}
+
+// TestNotify 获取是否校验推送配置并发送测试通知
+func (c *Config) TestNotify() bool {
+	return c.testNotify
+}
+
+// NotifyDedupWindow 获取通知去重时间窗口
+func (c *Config) NotifyDedupWindow() time.Duration {
+	return c.notifyDedupWindow
+}
+
+// CheckConcurrency 获取同时检查的镜像数量上限
+func (c *Config) CheckConcurrency() int {
+	return c.checkConcurrency
+}
+
+// UpdateConcurrency 获取同时重建的容器数量上限
+func (c *Config) UpdateConcurrency() int {
+	return c.updateConcurrency
+}
+
+// WaitReady 获取更新后等待新容器就绪的最长时间
+func (c *Config) WaitReady() time.Duration {
+	return c.waitReady
+}
+
+// Timezone 获取日志时间戳和 cron 调度使用的时区名称
+func (c *Config) Timezone() string {
+	return c.timezone
+}
+
+// Location 获取日志时间戳和 cron 调度使用的时区
+func (c *Config) Location() *time.Location {
+	return c.location
+}
+
+// Lang 获取日志和输出语言
+func (c *Config) Lang() string {
+	return c.lang
+}
+
+// ShowPullProgress 获取是否以 INFO 级别显示镜像拉取进度
+func (c *Config) ShowPullProgress() bool {
+	return c.showPullProgress
+}
+
+// StateFile 获取容器状态文件路径
+func (c *Config) StateFile() string {
+	return c.stateFile
+}
+
+// Cooldown 获取容器更新后的冷却期
+func (c *Config) Cooldown() time.Duration {
+	return c.cooldown
+}
+
+// Projects 获取要检查的 compose 项目列表
+func (c *Config) Projects() []string {
+	var projects []string
+	for _, project := range strings.Split(c.project, ",") {
+		if project = strings.TrimSpace(project); project != "" {
+			projects = append(projects, project)
+		}
+	}
+	return projects
+}
+
+// NoPull 获取是否跳过拉取、仅使用本地镜像重建
+func (c *Config) NoPull() bool {
+	return c.noPull
+}
+
+// CleanupOrphans 获取是否清理更新遗留的旧容器
+func (c *Config) CleanupOrphans() bool {
+	return c.cleanupOrphans
+}
+
+// InheritEntrypoint 获取更新时是否继承容器显式覆盖的 entrypoint 和 healthcheck
+func (c *Config) InheritEntrypoint() bool {
+	return c.inheritEntrypoint
+}
+
+// RunTimeout 获取单次运行的最长时间
+func (c *Config) RunTimeout() time.Duration {
+	return c.runTimeout
+}
+
+// WatchEvents 获取是否监听 Docker 容器事件触发检查
+func (c *Config) WatchEvents() bool {
+	return c.watchEvents
+}
+
+// ReportMarkdown 获取 Markdown 报告文件路径
+func (c *Config) ReportMarkdown() string {
+	return c.reportMarkdown
+}
+
+// PreRunHook 获取运行前执行的 shell 命令
+func (c *Config) PreRunHook() string {
+	return c.preRunHook
+}
+
+// PostRunHook 获取运行后执行的 shell 命令
+func (c *Config) PostRunHook() string {
+	return c.postRunHook
+}
+
+// SkipUnhealthy 获取是否跳过非健康状态容器的更新
+func (c *Config) SkipUnhealthy() bool {
+	return c.skipUnhealthy
+}
+
+// SkipRegistries 获取检查时忽略的 registry 列表
+func (c *Config) SkipRegistries() []string {
+	var registries []string
+	for _, registry := range strings.Split(c.skipRegistry, ",") {
+		if registry = strings.TrimSpace(registry); registry != "" {
+			registries = append(registries, registry)
+		}
+	}
+	return registries
+}
+
+// WebhookListen 获取接收更新 webhook 的监听地址
+func (c *Config) WebhookListen() string {
+	return c.webhookListen
+}
+
+// WebhookSecret 获取接收 webhook 时校验的共享密钥
+func (c *Config) WebhookSecret() string {
+	return c.webhookSecret
+}
+
+// SortBy 返回检查结果的排序方式，为空时按发现顺序
+func (c *Config) SortBy() string {
+	return c.sortBy
+}
+
+// Jitter 返回定时任务触发前随机等待的最大时长
+func (c *Config) Jitter() time.Duration {
+	return c.jitter
+}
+
+// CSVFile 返回导出 CSV 检查结果的文件路径
+func (c *Config) CSVFile() string {
+	return c.csvFile
+}
+
+// MinImageAge 返回触发更新所需的新镜像最小年龄
+func (c *Config) MinImageAge() time.Duration {
+	return c.minImageAge
+}
+
+// Images 返回直接检查的镜像列表
+func (c *Config) Images() []string {
+	var images []string
+	for _, image := range strings.Split(c.image, ",") {
+		if image = strings.TrimSpace(image); image != "" {
+			images = append(images, image)
+		}
+	}
+	return images
+}
+
+// PullTimeout 获取单次镜像拉取的超时时间
+func (c *Config) PullTimeout() time.Duration {
+	return c.pullTimeout
+}
+
+// MinUptime 获取容器参与检查所需的最短运行时长
+func (c *Config) MinUptime() time.Duration {
+	return c.minUptime
+}
+
+// InstanceName 获取实例名称，未配置时使用主机名
+func (c *Config) InstanceName() string {
+	if c.instanceName != "" {
+		return c.instanceName
+	}
+	hostname, _ := os.Hostname()
+	return hostname
+}
+
+// SemverTags 获取是否对版本号 tag 检查更高版本
+func (c *Config) SemverTags() bool {
+	return c.semverTags
+}
+
+// ConcurrencyPerRegistry 返回各 registry 同时检查的镜像数量上限，键为 registry 主机（Docker Hub 为 docker.io）
+func (c *Config) ConcurrencyPerRegistry() map[string]int {
+	limits := make(map[string]int)
+	for _, rule := range strings.Split(c.concurrencyPerRegistry, ",") {
+		registry, value, ok := strings.Cut(strings.TrimSpace(rule), "=")
+		if !ok {
+			continue
+		}
+		if n, err := strconv.Atoi(strings.TrimSpace(value)); err == nil && n > 0 {
+			limits[strings.TrimSpace(registry)] = n
+		}
+	}
+	return limits
+}
+
+// SemverAllowMajor 获取版本号 tag 是否允许跨主版本更新
+func (c *Config) SemverAllowMajor() bool {
+	return c.semverAllowMajor
+}
+
 // loadConfig 执行实际的配置加载逻辑
 func loadConfig() (*Config, error) {
 	// 创建 Viper 实例
@@ -120,6 +497,58 @@ func loadConfig() (*Config, error) {
 	v.SetDefault("no-restart", false)
 	v.SetDefault("include-stopped", false)
 	v.SetDefault("disabled-containers", "")
+	v.SetDefault("report-file", "")
+	v.SetDefault("log-file", "")
+	v.SetDefault("log-file-max-size", 10)
+	v.SetDefault("log-file-max-backups", 5)
+	v.SetDefault("log-stdout", true)
+	v.SetDefault("log-format", "text")
+	v.SetDefault("check-timeout", 5*time.Minute)
+	v.SetDefault("docker-host", "")
+	v.SetDefault("backup-before-update", false)
+	v.SetDefault("backup-keep", 3)
+	v.SetDefault("no-color", false)
+	v.SetDefault("quiet", false)
+	v.SetDefault("containers-file", "")
+	v.SetDefault("self-update", false)
+	v.SetDefault("self-update-grace", 10*time.Second)
+	v.SetDefault("registry-mirror", "")
+	v.SetDefault("exit-code-on-update", 2)
+	v.SetDefault("pull-rate-limit", 0)
+	v.SetDefault("test-notify", false)
+	v.SetDefault("notify-dedup-window", 0)
+	v.SetDefault("check-concurrency", 0)
+	v.SetDefault("update-concurrency", 1)
+	v.SetDefault("wait-ready", 0)
+	v.SetDefault("timezone", "")
+	v.SetDefault("lang", "zh")
+	v.SetDefault("show-pull-progress", false)
+	v.SetDefault("state-file", "")
+	v.SetDefault("cooldown", 0)
+	v.SetDefault("project", "")
+	v.SetDefault("no-pull", false)
+	v.SetDefault("cleanup-orphans", false)
+	v.SetDefault("inherit-entrypoint", true)
+	v.SetDefault("run-timeout", 0)
+	v.SetDefault("watch-events", false)
+	v.SetDefault("report-markdown", "")
+	v.SetDefault("pre-run-hook", "")
+	v.SetDefault("post-run-hook", "")
+	v.SetDefault("skip-unhealthy", false)
+	v.SetDefault("skip-registry", "")
+	v.SetDefault("webhook-listen", "")
+	v.SetDefault("webhook-secret", "")
+	v.SetDefault("sort-by", "")
+	v.SetDefault("jitter", 0)
+	v.SetDefault("csv-file", "")
+	v.SetDefault("min-image-age", 0)
+	v.SetDefault("image", "")
+	v.SetDefault("pull-timeout", 0)
+	v.SetDefault("min-uptime", 0)
+	v.SetDefault("instance-name", "")
+	v.SetDefault("semver-tags", false)
+	v.SetDefault("concurrency-per-registry", "")
+	v.SetDefault("semver-allow-major", false)
 
 	// 环境变量键名中的连字符替换为下划线
 	v.SetEnvKeyReplacer(strings.NewReplacer("-", "_"))
@@ -134,6 +563,58 @@ func loadConfig() (*Config, error) {
 	pflag.Bool("no-restart", false, "只更新镜像，不重启容器")
 	pflag.Bool("include-stopped", false, "检查时包含已停止的容器")
 	pflag.String("disabled-containers", "", "排除指定的容器，不进行检查和更新")
+	pflag.String("report-file", "", "将每次检查结果以 JSON Lines 格式追加写入指定文件")
+	pflag.String("log-file", "", "将日志同时写入指定文件")
+	pflag.Int("log-file-max-size", 10, "单个日志文件的大小上限（MB），超过后滚动")
+	pflag.Int("log-file-max-backups", 5, "保留的滚动日志文件数量")
+	pflag.Bool("log-stdout", true, "是否同时输出日志到标准输出")
+	pflag.String("log-format", "text", "日志输出格式 (text/json)")
+	pflag.Duration("check-timeout", 5*time.Minute, "单个镜像检查的超时时间")
+	pflag.String("docker-host", "", "Docker 守护进程地址，支持逗号分隔多个（默认读取 DOCKER_HOST）")
+	pflag.Bool("backup-before-update", false, "更新容器前将旧容器提交为带时间戳的备份镜像")
+	pflag.Int("backup-keep", 3, "每个容器保留的备份镜像数量")
+	pflag.Bool("no-color", false, "禁用终端颜色输出（非终端或设置 NO_COLOR 时自动禁用）")
+	pflag.Bool("quiet", false, "安静模式，只输出有更新或检查失败的镜像/容器以及统计信息")
+	pflag.String("containers-file", "", "从文件读取要检查的容器名称（每行一个，- 表示标准输入）")
+	pflag.Bool("self-update", false, "检查并更新 watchducker 自身容器")
+	pflag.Duration("self-update-grace", 10*time.Second, "自我更新时确认新容器稳定运行的等待时间")
+	pflag.String("registry-mirror", "", "镜像拉取重写规则，格式为 原前缀=镜像源前缀，逗号分隔多个")
+	pflag.Int("exit-code-on-update", 2, "--once 模式下有镜像更新时的退出码，默认为 2，设为 0 表示有更新时也按成功退出（检查或更新失败时退出码为 1）")
+	pflag.Int("pull-rate-limit", 0, "每分钟最多拉取镜像的次数，0 表示不限制")
+	pflag.Bool("test-notify", false, "校验推送配置并发送一条测试通知后退出")
+	pflag.Duration("notify-dedup-window", 0, "相同通知内容的去重时间窗口，窗口内不重复推送，0 表示不去重")
+	pflag.Int("check-concurrency", 0, "同时检查的镜像数量上限，0 表示不限制")
+	pflag.Int("update-concurrency", 1, "同时重建的容器数量上限，默认为 1 即串行更新")
+	pflag.Duration("wait-ready", 0, "更新后等待新容器运行且健康检查通过的最长时间，未就绪时恢复旧容器，0 表示不等待")
+	pflag.String("timezone", "", "日志时间戳和 cron 调度使用的时区（如 UTC、Asia/Shanghai），默认使用 TZ 指定的本地时区")
+	pflag.String("lang", "zh", "日志和输出语言 (zh/en)，默认为 zh")
+	pflag.Bool("show-pull-progress", false, "以 INFO 级别显示镜像拉取进度，无需将全局日志级别降到 DEBUG")
+	pflag.String("state-file", "", "记录每个容器上次检查和更新时间以及镜像摘要缓存的状态文件（JSON）")
+	pflag.Duration("cooldown", 0, "更新后的冷却期，冷却期内的容器跳过检查，需配合 --state-file 使用，0 表示不启用")
+	pflag.String("project", "", "只检查指定 compose 项目（com.docker.compose.project 标签）的容器，逗号分隔多个")
+	pflag.Bool("no-pull", false, "不从 registry 拉取，容器使用的镜像与本地同名镜像不一致时直接用本地镜像重建")
+	pflag.Bool("cleanup-orphans", false, "检查前清理更新中断时遗留的已停止旧容器（名称形如 原名称_watchducker_时间戳）")
+	pflag.Bool("inherit-entrypoint", true, "更新时继承容器显式覆盖的 entrypoint/cmd/healthcheck，设为 false 时使用新镜像的默认值，默认为 true")
+	pflag.Duration("run-timeout", 0, "单次运行（检查和更新）的最长时间，超时后取消所有进行中的检查和更新，0 表示不限制，默认为 0")
+	pflag.Bool("watch-events", false, "守护模式下监听 Docker 容器 create/start 事件，立即检查对应容器的镜像，与 cron 定时检查同时生效")
+	pflag.String("report-markdown", "", "运行结束时将所有主机的检查结果写成 Markdown 表格报告（覆盖写入指定文件）")
+	pflag.String("pre-run-hook", "", "每次运行开始前执行的 shell 命令，返回非 0 时跳过本次运行")
+	pflag.String("post-run-hook", "", "每次运行结束后执行的 shell 命令，可通过 WATCHDUCKER_UPDATED、WATCHDUCKER_FAILED、WATCHDUCKER_EXIT_CODE 环境变量获取运行结果")
+	pflag.Bool("skip-unhealthy", false, "跳过当前处于 unhealthy、starting 或 restarting 状态的容器更新，只更新稳定运行的容器")
+	pflag.String("skip-registry", "", "检查时忽略来自指定 registry 的镜像（如 registry.internal.com），逗号分隔多个")
+	pflag.String("webhook-listen", "", "守护模式下启动 HTTP 服务监听的地址（如 :8080），接收 POST /hook 请求后立即检查并更新使用指定镜像的容器，监听非本机地址时必须设置 --webhook-secret")
+	pflag.String("webhook-secret", "", "接收 webhook 时校验的共享密钥，请求需携带 Authorization: Bearer <密钥> 请求头")
+	pflag.String("sort-by", "", "检查结果的排序方式，设为 status 时按更新状态排序（有更新、失败在前，最新在后），默认按发现顺序")
+	pflag.Duration("jitter", 0, "定时任务每次触发前随机等待 0 到该时长，错开多实例对 registry 的请求，如 10m，默认为 0（不等待）")
+	pflag.String("csv-file", "", "将本次运行的容器检查结果导出为 CSV 文件（覆盖已有文件），便于用 Excel 查看")
+	pflag.Duration("min-image-age", 0, "只有创建时间超过该时长的新镜像才触发更新，避免更新到刚发布可能不稳定的版本，如 24h，默认为 0（不限制）")
+	pflag.String("image", "", "直接检查指定镜像是否有更新（逗号分隔，如 nginx:latest,redis:7），不查找容器也不执行更新")
+	pflag.Duration("pull-timeout", 0, "单次镜像拉取的超时时间（如 10m），超时则中止该镜像的拉取并标记失败，默认不限制")
+	pflag.Duration("min-uptime", 0, "只检查已连续运行超过该时长的容器（如 30m），跳过刚启动或反复重启的容器，默认不限制")
+	pflag.String("instance-name", "", "推送通知中显示的实例名称，用于区分多个实例，默认为主机名")
+	pflag.Bool("semver-tags", false, "对版本号 tag（如 1.25.3、v2.1-alpine）的镜像检查 registry 上是否有同系列、同一主版本的更高版本 tag 并更新到该 tag，latest 等其他 tag 仍按摘要比对")
+	pflag.String("concurrency-per-registry", "", "按 registry 限制同时检查的镜像数量，格式为 registry=数量，逗号分隔多个（如 docker.io=2,harbor.local=10），未列出的 registry 只受 --check-concurrency 限制")
+	pflag.Bool("semver-allow-major", false, "配合 --semver-tags 使用，允许更新到更高主版本的 tag（如 postgres 15 到 17），默认只在同一主版本内更新")
 
 	// 解析命令行参数
 	pflag.Parse()
@@ -142,17 +623,79 @@ func loadConfig() (*Config, error) {
 	v.BindPFlags(pflag.CommandLine)
 
 	config := &Config{
-		containerNames:     pflag.Args(), // 获取位置参数（容器名称）
-		logLevel:           v.GetString("LOG_LEVEL"),
-		checkAll:           v.GetBool("all"),
-		checkLabel:         v.GetBool("label"),
-		checkLabelReversed: v.GetBool("label-reversed"),
-		noRestart:          v.GetBool("no-restart"),
-		runOnce:            v.GetBool("once"),
-		cronExpression:     v.GetString("cron"),
-		cleanUp:            v.GetBool("clean"),
-		includeStopped:     v.GetBool("include-stopped"),
-		disabledContainers: v.GetString("disabled-containers"),
+		containerNames:         pflag.Args(), // 获取位置参数（容器名称）
+		logLevel:               v.GetString("LOG_LEVEL"),
+		checkAll:               v.GetBool("all"),
+		checkLabel:             v.GetBool("label"),
+		checkLabelReversed:     v.GetBool("label-reversed"),
+		noRestart:              v.GetBool("no-restart"),
+		runOnce:                v.GetBool("once"),
+		cronSet:                pflag.CommandLine.Changed("cron") || os.Getenv("WATCHDUCKER_CRON") != "",
+		cronExpression:         v.GetString("cron"),
+		cleanUp:                v.GetBool("clean"),
+		includeStopped:         v.GetBool("include-stopped"),
+		disabledContainers:     v.GetString("disabled-containers"),
+		reportFile:             v.GetString("report-file"),
+		logFile:                v.GetString("log-file"),
+		logFileMaxSize:         v.GetInt("log-file-max-size"),
+		logFileMaxBackups:      v.GetInt("log-file-max-backups"),
+		logStdout:              v.GetBool("log-stdout"),
+		logFormat:              v.GetString("log-format"),
+		checkTimeout:           v.GetDuration("check-timeout"),
+		dockerHost:             v.GetString("docker-host"),
+		backupBeforeUpdate:     v.GetBool("backup-before-update"),
+		backupKeep:             v.GetInt("backup-keep"),
+		noColor:                v.GetBool("no-color"),
+		quiet:                  v.GetBool("quiet"),
+		containersFile:         v.GetString("containers-file"),
+		selfUpdate:             v.GetBool("self-update"),
+		selfUpdateGrace:        v.GetDuration("self-update-grace"),
+		registryMirror:         v.GetString("registry-mirror"),
+		exitCodeOnUpdate:       v.GetInt("exit-code-on-update"),
+		pullRateLimit:          v.GetInt("pull-rate-limit"),
+		testNotify:             v.GetBool("test-notify"),
+		notifyDedupWindow:      v.GetDuration("notify-dedup-window"),
+		checkConcurrency:       v.GetInt("check-concurrency"),
+		updateConcurrency:      v.GetInt("update-concurrency"),
+		waitReady:              v.GetDuration("wait-ready"),
+		timezone:               v.GetString("timezone"),
+		lang:                   v.GetString("lang"),
+		showPullProgress:       v.GetBool("show-pull-progress"),
+		stateFile:              v.GetString("state-file"),
+		cooldown:               v.GetDuration("cooldown"),
+		project:                v.GetString("project"),
+		noPull:                 v.GetBool("no-pull"),
+		cleanupOrphans:         v.GetBool("cleanup-orphans"),
+		inheritEntrypoint:      v.GetBool("inherit-entrypoint"),
+		runTimeout:             v.GetDuration("run-timeout"),
+		watchEvents:            v.GetBool("watch-events"),
+		reportMarkdown:         v.GetString("report-markdown"),
+		preRunHook:             v.GetString("pre-run-hook"),
+		postRunHook:            v.GetString("post-run-hook"),
+		skipUnhealthy:          v.GetBool("skip-unhealthy"),
+		skipRegistry:           v.GetString("skip-registry"),
+		webhookListen:          v.GetString("webhook-listen"),
+		webhookSecret:          v.GetString("webhook-secret"),
+		sortBy:                 v.GetString("sort-by"),
+		jitter:                 v.GetDuration("jitter"),
+		csvFile:                v.GetString("csv-file"),
+		minImageAge:            v.GetDuration("min-image-age"),
+		image:                  v.GetString("image"),
+		pullTimeout:            v.GetDuration("pull-timeout"),
+		minUptime:              v.GetDuration("min-uptime"),
+		instanceName:           v.GetString("instance-name"),
+		semverTags:             v.GetBool("semver-tags"),
+		concurrencyPerRegistry: v.GetString("concurrency-per-registry"),
+		semverAllowMajor:       v.GetBool("semver-allow-major"),
+	}
+
+	// 合并文件或标准输入中的容器名称
+	if config.containersFile != "" {
+		names, err := readContainerNames(config.containersFile)
+		if err != nil {
+			return nil, err
+		}
+		config.containerNames = append(config.containerNames, names...)
 	}
 
 	// 设置日志级别
@@ -160,6 +703,39 @@ func loadConfig() (*Config, error) {
 		logger.SetLevel(config.logLevel)
 	}
 
+	// 非终端、设置了 NO_COLOR 或 --no-color 时禁用颜色
+	colorEnabled := !config.noColor && os.Getenv("NO_COLOR") == "" && utils.IsTerminal(os.Stdout)
+	logger.SetColor(colorEnabled)
+	utils.SetColor(colorEnabled)
+	utils.SetQuiet(config.quiet)
+
+	// 设置日志和输出语言
+	i18n.SetLang(config.lang)
+
+	// 解析时区，日志时间戳和 cron 调度统一使用该时区
+	config.location = time.Local
+	if config.timezone != "" {
+		loc, err := time.LoadLocation(config.timezone)
+		if err != nil {
+			return nil, fmt.Errorf("无效的时区 '%s': %w", config.timezone, err)
+		}
+		config.location = loc
+	}
+	logger.SetLocation(config.location)
+
+	// 设置日志输出格式
+	logger.SetFormat(config.logFormat)
+
+	// 设置通知去重窗口
+	notify.SetDedupWindow(config.notifyDedupWindow)
+
+	// 设置日志文件输出
+	if config.logFile != "" {
+		if err := logger.SetFile(config.logFile, config.logFileMaxSize, config.logFileMaxBackups, config.logStdout); err != nil {
+			return nil, err
+		}
+	}
+
 	// 验证配置有效性
 	if err := config.validate(); err != nil {
 		PrintUsage()
@@ -169,11 +745,104 @@ func loadConfig() (*Config, error) {
 	return config, nil
 }
 
+// readContainerNames 读取容器名称列表，每行一个，忽略空行和 # 开头的注释
+func readContainerNames(path string) ([]string, error) {
+	var reader io.Reader = os.Stdin
+	if path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("打开容器列表文件失败: %w", err)
+		}
+		defer file.Close()
+		reader = file
+	}
+
+	var names []string
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		names = append(names, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("读取容器列表失败: %w", err)
+	}
+
+	return names, nil
+}
+
 // Validate 验证配置的有效性
 func (c *Config) validate() error {
+	// 日志格式写错时静默退回 text 会让依赖 JSON 日志的采集管道失效，启动时直接报错
+	switch strings.ToLower(c.logFormat) {
+	case "", "text", "json":
+	default:
+		return fmt.Errorf("无效的日志格式 '%s'，仅支持 text 或 json", c.logFormat)
+	}
+
+	// 测试通知模式不执行检查，无需其他选项
+	if c.testNotify {
+		return nil
+	}
+
 	// 验证至少需要一种检查方式
-	if len(c.containerNames) == 0 && !c.checkLabel && !c.checkAll && !c.checkLabelReversed {
-		return fmt.Errorf("必须指定容器名称或使用 --label 或 --all 或 --label-reversed 选项")
+	if len(c.containerNames) == 0 && len(c.Projects()) == 0 && len(c.Images()) == 0 && !c.checkLabel && !c.checkAll && !c.checkLabelReversed && !c.selfUpdate {
+		return fmt.Errorf("必须指定容器名称或使用 --project 或 --image 或 --label 或 --all 或 --label-reversed 或 --self-update 选项")
+	}
+
+	// --once 优先于 --cron，同时设置时只执行一次
+	if c.runOnce && c.cronSet {
+		logger.Warn("同时设置了 --once 和 --cron，将只执行一次并忽略 --cron")
+	}
+	if c.runOnce && c.watchEvents {
+		logger.Warn("--watch-events 仅在守护模式下生效，--once 模式下将被忽略")
+	}
+	if c.runOnce && c.webhookListen != "" {
+		logger.Warn("--webhook-listen 仅在守护模式下生效，--once 模式下将被忽略")
+	}
+
+	// 未设置密钥时任何能访问监听地址的人都能触发更新，只允许监听本机回环地址
+	if !c.runOnce && c.webhookListen != "" && c.webhookSecret == "" {
+		if !isLoopbackAddr(c.webhookListen) {
+			return fmt.Errorf("--webhook-listen 监听非本机地址 '%s' 时必须设置 --webhook-secret", c.webhookListen)
+		}
+		logger.Warn("未设置 --webhook-secret，webhook 接口不校验请求来源")
+	}
+
+	// 定时模式下提前验证 cron 表达式
+	if !c.runOnce {
+		if _, err := cron.ParseStandard(c.cronExpression); err != nil {
+			return fmt.Errorf("无效的 cron 表达式 '%s': %w", c.cronExpression, err)
+		}
+	}
+
+	// 冷却期依赖状态文件中记录的更新时间
+	if c.cooldown > 0 && c.stateFile == "" {
+		logger.Warn("--cooldown 需要配合 --state-file 使用，本次不启用冷却期")
+	}
+
+	if c.sortBy != "" && c.sortBy != "status" {
+		return fmt.Errorf("无效的排序方式 '%s'，仅支持 status", c.sortBy)
+	}
+
+	// 验证镜像拉取重写规则格式
+	for _, rule := range c.RegistryMirrors() {
+		if from, to, ok := strings.Cut(rule, "="); !ok || from == "" || to == "" {
+			return fmt.Errorf("无效的镜像拉取重写规则 '%s'，格式应为 原前缀=镜像源前缀", rule)
+		}
+	}
+
+	// 验证按 registry 的并发限制格式
+	for _, rule := range strings.Split(c.concurrencyPerRegistry, ",") {
+		if rule = strings.TrimSpace(rule); rule == "" {
+			continue
+		}
+		registry, value, ok := strings.Cut(rule, "=")
+		if n, err := strconv.Atoi(strings.TrimSpace(value)); !ok || strings.TrimSpace(registry) == "" || err != nil || n <= 0 {
+			return fmt.Errorf("无效的 registry 并发限制 '%s'，格式应为 registry=正整数", rule)
+		}
 	}
 
 	return nil
@@ -194,6 +863,58 @@ func PrintUsage() {
 	fmt.Println("  --no-restart          只更新镜像，不重启容器")
 	fmt.Println("  --include-stopped     检查时包含已停止的容器（默认仅检查运行中容器）")
 	fmt.Println("  --disabled-containers 排除指定的容器，不进行检查和更新")
+	fmt.Println("  --report-file         将每次检查结果以 JSON Lines 格式追加写入指定文件")
+	fmt.Println("  --log-file            将日志同时写入指定文件，按大小自动滚动")
+	fmt.Println("  --log-file-max-size   单个日志文件的大小上限（MB），默认为 10")
+	fmt.Println("  --log-file-max-backups 保留的滚动日志文件数量，默认为 5")
+	fmt.Println("  --log-stdout          是否同时输出日志到标准输出，默认为 true")
+	fmt.Println("  --log-format          日志输出格式 (text/json)，默认为 text")
+	fmt.Println("  --check-timeout       单个镜像检查的超时时间，默认为 5m")
+	fmt.Println("  --docker-host         Docker 守护进程地址，支持逗号分隔多个（默认读取 DOCKER_HOST）")
+	fmt.Println("  --backup-before-update 更新容器前将旧容器提交为带时间戳的备份镜像")
+	fmt.Println("  --backup-keep         每个容器保留的备份镜像数量，默认为 3")
+	fmt.Println("  --no-color            禁用终端颜色输出（非终端或设置 NO_COLOR 时自动禁用）")
+	fmt.Println("  --quiet               安静模式，只输出有更新或检查失败的镜像/容器以及统计信息")
+	fmt.Println("  --containers-file     从文件读取要检查的容器名称（每行一个，- 表示标准输入）")
+	fmt.Println("  --self-update         检查并更新 watchducker 自身容器")
+	fmt.Println("  --self-update-grace   自我更新时确认新容器稳定运行的等待时间，默认为 10s")
+	fmt.Println("  --registry-mirror     镜像拉取重写规则，格式为 原前缀=镜像源前缀，逗号分隔多个")
+	fmt.Println("  --exit-code-on-update --once 模式下有镜像更新时的退出码，默认为 2，设为 0 表示有更新时也按成功退出（检查或更新失败时退出码为 1）")
+	fmt.Println("  --pull-rate-limit     每分钟最多拉取镜像的次数，0 表示不限制")
+	fmt.Println("  --test-notify         校验推送配置并发送一条测试通知后退出")
+	fmt.Println("  --notify-dedup-window 相同通知内容的去重时间窗口，窗口内不重复推送，0 表示不去重")
+	fmt.Println("  --check-concurrency   同时检查的镜像数量上限，0 表示不限制")
+	fmt.Println("  --update-concurrency  同时重建的容器数量上限，默认为 1 即串行更新")
+	fmt.Println("  --wait-ready          更新后等待新容器运行且健康检查通过的最长时间，未就绪时恢复旧容器，0 表示不等待")
+	fmt.Println("  --timezone            日志时间戳和 cron 调度使用的时区（如 UTC、Asia/Shanghai），默认使用 TZ 指定的本地时区")
+	fmt.Println("  --lang                日志和输出语言 (zh/en)，默认为 zh")
+	fmt.Println("  --show-pull-progress  以 INFO 级别显示镜像拉取进度，无需将全局日志级别降到 DEBUG")
+	fmt.Println("  --state-file          记录每个容器上次检查和更新时间以及镜像摘要缓存的状态文件（JSON）")
+	fmt.Println("  --cooldown            更新后的冷却期，冷却期内的容器跳过检查，需配合 --state-file 使用，0 表示不启用")
+	fmt.Println("  --project             只检查指定 compose 项目（com.docker.compose.project 标签）的容器，逗号分隔多个")
+	fmt.Println("  --no-pull             不从 registry 拉取，容器使用的镜像与本地同名镜像不一致时直接用本地镜像重建")
+	fmt.Println("  --cleanup-orphans     检查前清理更新中断时遗留的已停止旧容器（名称形如 原名称_watchducker_时间戳）")
+	fmt.Println("  --inherit-entrypoint  更新时继承容器显式覆盖的 entrypoint/cmd/healthcheck，设为 false 时使用新镜像的默认值，默认为 true")
+	fmt.Println("  --run-timeout         单次运行（检查和更新）的最长时间，超时后取消所有进行中的检查和更新，0 表示不限制，默认为 0")
+	fmt.Println("  --watch-events        守护模式下监听 Docker 容器 create/start 事件，立即检查对应容器的镜像，与 cron 定时检查同时生效")
+	fmt.Println("  --report-markdown     运行结束时将所有主机的检查结果写成 Markdown 表格报告（覆盖写入指定文件）")
+	fmt.Println("  --pre-run-hook        每次运行开始前执行的 shell 命令，返回非 0 时跳过本次运行")
+	fmt.Println("  --post-run-hook       每次运行结束后执行的 shell 命令，可通过 WATCHDUCKER_UPDATED、WATCHDUCKER_FAILED、WATCHDUCKER_EXIT_CODE 环境变量获取运行结果")
+	fmt.Println("  --skip-unhealthy      跳过当前处于 unhealthy、starting 或 restarting 状态的容器更新，只更新稳定运行的容器")
+	fmt.Println("  --skip-registry       检查时忽略来自指定 registry 的镜像（如 registry.internal.com），逗号分隔多个")
+	fmt.Println("  --webhook-listen      守护模式下启动 HTTP 服务监听的地址（如 :8080），接收 POST /hook 请求后立即检查并更新使用指定镜像的容器，监听非本机地址时必须设置 --webhook-secret")
+	fmt.Println("  --webhook-secret      接收 webhook 时校验的共享密钥，请求需携带 Authorization: Bearer <密钥> 请求头")
+	fmt.Println("  --sort-by             检查结果的排序方式，设为 status 时按更新状态排序（有更新、失败在前，最新在后），默认按发现顺序")
+	fmt.Println("  --jitter              定时任务每次触发前随机等待 0 到该时长，错开多实例对 registry 的请求，如 10m，默认为 0（不等待）")
+	fmt.Println("  --csv-file            将本次运行的容器检查结果导出为 CSV 文件（覆盖已有文件），便于用 Excel 查看")
+	fmt.Println("  --min-image-age       只有创建时间超过该时长的新镜像才触发更新，避免更新到刚发布可能不稳定的版本，如 24h，默认为 0（不限制）")
+	fmt.Println("  --image               直接检查指定镜像是否有更新（逗号分隔，如 nginx:latest,redis:7），不查找容器也不执行更新")
+	fmt.Println("  --pull-timeout        单次镜像拉取的超时时间（如 10m），超时则中止该镜像的拉取并标记失败，默认不限制")
+	fmt.Println("  --min-uptime          只检查已连续运行超过该时长的容器（如 30m），跳过刚启动或反复重启的容器，默认不限制")
+	fmt.Println("  --instance-name       推送通知中显示的实例名称，用于区分多个实例，默认为主机名")
+	fmt.Println("  --semver-tags         对版本号 tag（如 1.25.3、v2.1-alpine）的镜像检查 registry 上是否有同系列、同一主版本的更高版本 tag 并更新到该 tag，latest 等其他 tag 仍按摘要比对")
+	fmt.Println("  --concurrency-per-registry 按 registry 限制同时检查的镜像数量，格式为 registry=数量，逗号分隔多个（如 docker.io=2,harbor.local=10），未列出的 registry 只受 --check-concurrency 限制")
+	fmt.Println("  --semver-allow-major  配合 --semver-tags 使用，允许更新到更高主版本的 tag（如 postgres 15 到 17），默认只在同一主版本内更新")
 	fmt.Println()
 	fmt.Println("环境变量:")
 	fmt.Println("  WATCHDUCKER_LOG_LEVEL           设置日志级别 (DEBUG/INFO/WARN/ERROR)")
@@ -205,6 +926,58 @@ func PrintUsage() {
 	fmt.Println("  WATCHDUCKER_NO_RESTART          等同于 --no-restart 选项")
 	fmt.Println("  WATCHDUCKER_INCLUDE_STOPPED     等同于 --include-stopped 选项")
 	fmt.Println("  WATCHDUCKER_DISABLED_CONTAINERS 等同于 --disabled-containers 选项")
+	fmt.Println("  WATCHDUCKER_REPORT_FILE         等同于 --report-file 选项")
+	fmt.Println("  WATCHDUCKER_LOG_FILE            等同于 --log-file 选项")
+	fmt.Println("  WATCHDUCKER_LOG_FILE_MAX_SIZE   等同于 --log-file-max-size 选项")
+	fmt.Println("  WATCHDUCKER_LOG_FILE_MAX_BACKUPS 等同于 --log-file-max-backups 选项")
+	fmt.Println("  WATCHDUCKER_LOG_STDOUT          等同于 --log-stdout 选项")
+	fmt.Println("  WATCHDUCKER_LOG_FORMAT          等同于 --log-format 选项")
+	fmt.Println("  WATCHDUCKER_CHECK_TIMEOUT       等同于 --check-timeout 选项")
+	fmt.Println("  WATCHDUCKER_DOCKER_HOST         等同于 --docker-host 选项")
+	fmt.Println("  WATCHDUCKER_BACKUP_BEFORE_UPDATE 等同于 --backup-before-update 选项")
+	fmt.Println("  WATCHDUCKER_BACKUP_KEEP         等同于 --backup-keep 选项")
+	fmt.Println("  WATCHDUCKER_NO_COLOR            等同于 --no-color 选项")
+	fmt.Println("  WATCHDUCKER_QUIET               等同于 --quiet 选项")
+	fmt.Println("  WATCHDUCKER_CONTAINERS_FILE     等同于 --containers-file 选项")
+	fmt.Println("  WATCHDUCKER_SELF_UPDATE         等同于 --self-update 选项")
+	fmt.Println("  WATCHDUCKER_SELF_UPDATE_GRACE   等同于 --self-update-grace 选项")
+	fmt.Println("  WATCHDUCKER_REGISTRY_MIRROR     等同于 --registry-mirror 选项")
+	fmt.Println("  WATCHDUCKER_EXIT_CODE_ON_UPDATE 等同于 --exit-code-on-update 选项")
+	fmt.Println("  WATCHDUCKER_PULL_RATE_LIMIT     等同于 --pull-rate-limit 选项")
+	fmt.Println("  WATCHDUCKER_TEST_NOTIFY         等同于 --test-notify 选项")
+	fmt.Println("  WATCHDUCKER_NOTIFY_DEDUP_WINDOW 等同于 --notify-dedup-window 选项")
+	fmt.Println("  WATCHDUCKER_CHECK_CONCURRENCY   等同于 --check-concurrency 选项")
+	fmt.Println("  WATCHDUCKER_UPDATE_CONCURRENCY  等同于 --update-concurrency 选项")
+	fmt.Println("  WATCHDUCKER_WAIT_READY          等同于 --wait-ready 选项")
+	fmt.Println("  WATCHDUCKER_TIMEZONE            等同于 --timezone 选项")
+	fmt.Println("  WATCHDUCKER_LANG                等同于 --lang 选项")
+	fmt.Println("  WATCHDUCKER_SHOW_PULL_PROGRESS  等同于 --show-pull-progress 选项")
+	fmt.Println("  WATCHDUCKER_STATE_FILE          等同于 --state-file 选项")
+	fmt.Println("  WATCHDUCKER_COOLDOWN            等同于 --cooldown 选项")
+	fmt.Println("  WATCHDUCKER_PROJECT             等同于 --project 选项")
+	fmt.Println("  WATCHDUCKER_NO_PULL             等同于 --no-pull 选项")
+	fmt.Println("  WATCHDUCKER_CLEANUP_ORPHANS     等同于 --cleanup-orphans 选项")
+	fmt.Println("  WATCHDUCKER_INHERIT_ENTRYPOINT  等同于 --inherit-entrypoint 选项")
+	fmt.Println("  WATCHDUCKER_RUN_TIMEOUT         等同于 --run-timeout 选项")
+	fmt.Println("  WATCHDUCKER_WATCH_EVENTS        等同于 --watch-events 选项")
+	fmt.Println("  WATCHDUCKER_REPORT_MARKDOWN     等同于 --report-markdown 选项")
+	fmt.Println("  WATCHDUCKER_PRE_RUN_HOOK        等同于 --pre-run-hook 选项")
+	fmt.Println("  WATCHDUCKER_POST_RUN_HOOK       等同于 --post-run-hook 选项")
+	fmt.Println("  WATCHDUCKER_SKIP_UNHEALTHY      等同于 --skip-unhealthy 选项")
+	fmt.Println("  WATCHDUCKER_SKIP_REGISTRY       等同于 --skip-registry 选项")
+	fmt.Println("  WATCHDUCKER_WEBHOOK_LISTEN      等同于 --webhook-listen 选项")
+	fmt.Println("  WATCHDUCKER_WEBHOOK_SECRET      等同于 --webhook-secret 选项")
+	fmt.Println("  WATCHDUCKER_SORT_BY             等同于 --sort-by 选项")
+	fmt.Println("  WATCHDUCKER_JITTER              等同于 --jitter 选项")
+	fmt.Println("  WATCHDUCKER_CSV_FILE            等同于 --csv-file 选项")
+	fmt.Println("  WATCHDUCKER_MIN_IMAGE_AGE       等同于 --min-image-age 选项")
+	fmt.Println("  WATCHDUCKER_IMAGE               等同于 --image 选项")
+	fmt.Println("  WATCHDUCKER_PULL_TIMEOUT        等同于 --pull-timeout 选项")
+	fmt.Println("  WATCHDUCKER_MIN_UPTIME          等同于 --min-uptime 选项")
+	fmt.Println("  WATCHDUCKER_INSTANCE_NAME       等同于 --instance-name 选项")
+	fmt.Println("  WATCHDUCKER_SEMVER_TAGS         等同于 --semver-tags 选项")
+	fmt.Println("  WATCHDUCKER_CONCURRENCY_PER_REGISTRY 等同于 --concurrency-per-registry 选项")
+	fmt.Println("  WATCHDUCKER_SEMVER_ALLOW_MAJOR  等同于 --semver-allow-major 选项")
 	fmt.Println()
 	fmt.Println("参数:")
 	fmt.Println("  要检查的容器名称列表（支持多个）  <容器1> <容器2> ... ")
@@ -222,11 +995,30 @@ func PrintUsage() {
 	fmt.Println("  # 检查没有 watchducker.update=true 标签的容器")
 	fmt.Println("  watchducker --label-reversed --once")
 	fmt.Println()
+	fmt.Println("  # 检查所有标签容器，并更新 watchducker 自身")
+	fmt.Println("  watchducker --label --self-update --once")
+	fmt.Println()
 	fmt.Println("  # 定时执行示例")
 	fmt.Println("  watchducker --cron \"0 2 * * *\" --label --clean                # 每天凌晨2点检查更新所有标签容器，清理悬空镜像")
 	fmt.Println("  watchducker --cron \"*/30 * * * *\" nginx redis                 # 每30分钟检查更新指定nginx、redis容器")
 	fmt.Println("  watchducker --cron \"@daily\" --all --disabled-containers mysql # 每天检查更新所有容器，但排除mysql")
 	fmt.Println()
 	fmt.Println("说明:")
-	fmt.Println("  - 优先级：指定容器 > --all > --label-reversed > --label")
+	fmt.Println("  - 优先级：指定容器 > --project > --all > --label-reversed > --label")
+	fmt.Println("  - 同时指定容器和 --label 时，检查两者的并集")
+	fmt.Println("  - 容器名称支持通配符，如 'web-*'")
+	fmt.Println("  - 运行模式：设置 --once 时只执行一次并忽略 --cron，未设置 --once 时按 --cron 定时执行")
+}
+
+// isLoopbackAddr 判断监听地址是否只绑定本机回环地址，省略主机（如 :8080）表示监听所有网卡
+func isLoopbackAddr(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
 }
@@ -0,0 +1,166 @@
+package i18n
+
+// en 英文消息表，键为中文原文（含格式化占位符）
+var en = map[string]string{
+	// 检查流程
+	"开始检查 Docker 主机: %s":                               "Checking Docker host: %s",
+	"开始根据容器名称检查镜像更新: %v":                               "Checking image updates by container name: %v",
+	"开始根据标签检查镜像更新: %s=%s":                              "Checking image updates by label: %s=%s",
+	"开始检查指定容器 %v 以及带有标签 %s=%s 的容器":                     "Checking containers %v and containers with label %s=%s",
+	"开始检查 compose 项目的容器: %v":                           "Checking containers of compose projects: %v",
+	"开始检查所有容器的镜像更新":                                    "Checking image updates for all containers",
+	"开始检查使用镜像 %v 的容器":                                  "Checking containers using images %v",
+	"开始检查指定镜像: %v":                                     "Checking images %v",
+	"开始检查没有 %s=%s 标签的容器":                               "Checking containers without label %s=%s",
+	"被排除的容器: %v":                                       "Excluded containers: %v",
+	"容器 %s 正在重启，跳过检查":                                  "Container %s is restarting, skipping",
+	"获取容器 %s 的启动时间失败，跳过检查: %v":                         "Failed to get start time of container %s, skipping: %v",
+	"容器 %s 已运行 %v，未达到最短运行时长 %v，跳过检查":                   "Container %s has been up for %v, less than the minimum uptime %v, skipping",
+	"容器 %s 最近已更新，处于冷却期内，跳过检查":                          "Container %s was updated recently and is in cooldown, skipping",
+	"跳过被排除的容器: %s":                                     "Skipping excluded container: %s",
+	"跳过带有标签 %s=%s 的容器: %s":                             "Skipping container with label %s=%s: %s",
+	"镜像 %s 无法解析: %v":                                   "Unable to resolve image %s: %v",
+	"未找到匹配的容器":                                         "No matching containers found",
+	"找到 %d 个容器，开始检查镜像更新":                               "Found %d containers, checking for image updates",
+	"开始检查镜像: %s":                                       "Checking image: %s",
+	"容器 %s 的镜像 %s 通过 digest 固定，跳过检查":                   "Image %[2]s of container %[1]s is pinned by digest, skipping",
+	"容器 %s 的镜像 %s 来自被忽略的 registry %s，跳过检查":             "Image %[2]s of container %[1]s is from ignored registry %[3]s, skipping",
+	"镜像 %s 为本地构建镜像，跳过检查":                               "Image %s is built locally, skipping",
+	"镜像 %s 在 registry 上不存在，视为本地镜像跳过检查":                 "Image %s does not exist in the registry, treating it as local only and skipping",
+	"存在尚未切换到目标镜像 %s 的容器，标记为需要更新":                       "Some containers have not switched to target image %s yet, marking it as updated",
+	"镜像 %s 有更高版本的 tag: %s":                             "Image %s has a newer tag: %s",
+	"镜像 %s 的新版本创建于 %v 前，未达到最小镜像年龄 %v，暂不更新":             "New version of image %s was created %v ago, younger than the minimum image age %v, not updating yet",
+	"恢复镜像 %s 的标签失败，新版本将不会再被检测为更新: %v":                  "Failed to restore the tag of image %s, the new version will not be detected as an update again: %v",
+	"本地不存在镜像 %s，已拉取作为比对基线":                             "Image %s not found locally, pulled as baseline",
+	"无法确认镜像 %s 是否有更新: %v":                              "Unable to determine whether image %s has an update: %v",
+	"检查过程中出现 %d 个错误":                                   "%d errors occurred during the check",
+	"镜像检查完成: 更新 %d, 最新 %d, 跳过 %d, 未知 %d, 失败 %d, 耗时 %v": "Image check finished: %d updated, %d up to date, %d skipped, %d unknown, %d failed, took %v",
+	"容器检查过程中出现错误: %v":                                  "Error while checking containers: %v",
+
+	// 更新流程
+	"发现 %d 个容器需要更新，开始自动更新流程":               "Found %d containers to update, starting automatic update",
+	"没有需要更新的容器":                            "No containers need updating",
+	"没有找到需要更新的容器":                          "No containers found to update",
+	"开始批量更新 %d 个容器":                        "Updating %d containers",
+	"开始更新容器 %s (%s) 到新镜像 %s":               "Updating container %s (%s) to new image %s",
+	"容器 %s 已成功更新到新镜像 %s，新容器ID: %s":         "Container %s updated to new image %s, new container ID: %s",
+	"容器 %s 原状态为 %s，更新后保持停止":                "Container %s was %s, leaving it stopped after update",
+	"容器 %s 处于暂停状态，先恢复运行再更新":                "Container %s is paused, unpausing it before update",
+	"获取 volumes-from 容器 %s 信息失败，跳过卷迁移: %v": "Failed to inspect volumes-from container %s, skipping volume migration: %v",
+	"重新暂停容器 %s 失败: %v":                     "Failed to pause container %s again: %v",
+	"容器 %s 的镜像 %s 没有找到对应的新镜像，跳过更新":         "No new image found for image %[2]s of container %[1]s, skipping update",
+	"更新容器 %s 失败: %v":                       "Failed to update container %s: %v",
+	"容器 %s 更新失败，开始恢复旧容器":                   "Update of container %s failed, restoring the old container",
+	"旧容器 %s 已恢复":                           "Old container %s restored",
+	"更新已取消，跳过剩余容器":                         "Update cancelled, skipping remaining containers",
+	"批量更新完成，成功更新 %d 个容器":                   "Batch update finished, %d containers updated",
+	"容器更新过程中出现错误: %v":                      "Error while updating containers: %v",
+	"删除旧容器 %s (%s) 失败，请手动清理: %v":           "Failed to remove old container %s (%s), please clean it up manually: %v",
+	"等待新容器 %s 就绪，最长 %v":                    "Waiting up to %[2]v for new container %[1]s to become ready",
+	"新容器 %s 已就绪":                           "New container %s is ready",
+	"探测健康检查地址 %s":                          "Probing healthcheck URL %s",
+	"健康检查地址 %s 探测成功":                       "Healthcheck URL %s is healthy",
+	"容器 %s 已备份为镜像 %s":                      "Container %s backed up as image %s",
+	"已清理容器 %s 的旧镜像 %s":                     "Removed old image %[2]s of container %[1]s",
+	"开始清理悬空镜像":                             "Cleaning up dangling images",
+	"悬空镜像清理完成":                             "Dangling images cleaned up",
+	"清理悬空镜像失败: %v":                         "Failed to clean up dangling images: %v",
+	"跳过自身容器 %s，自身仅通过自我更新流程更新":              "Skipping own container %s, it is only updated via self-update",
+	"容器 %s 当前未稳定运行（%s），跳过更新":               "Container %s is not running stably (%s), skipping update",
+	"容器 %s 的资源限制已按标签更新":                    "Resource limits of container %s updated from labels",
+	"按标签更新容器资源限制时出现错误: %v":                 "Error while updating container resource limits from labels: %v",
+	"已按标签更新 %d 个容器的资源限制":                   "Updated resource limits of %d containers from labels",
+
+	// 运行与调度
+	"初始化失败: %v": "Initialization failed: %v",
+	"创建检查器失败，跳过 Docker 主机 %s: %v":               "Failed to create checker, skipping Docker host %s: %v",
+	"创建操作器失败，跳过 Docker 主机 %s 的容器更新: %v":         "Failed to create operator, skipping container updates on Docker host %s: %v",
+	"创建操作器失败: %v":                               "Failed to create operator: %v",
+	"定时任务开始执行":                                  "Scheduled run started",
+	"定时任务已触发，随机等待 %v 后执行":                       "Scheduled run triggered, waiting a random %v before running",
+	"定时任务执行完成":                                  "Scheduled run finished",
+	"已从 panic 中恢复: %v\n%s":                      "Recovered from panic: %v\n%s",
+	"执行钩子: %s":                                  "Running hook: %s",
+	"钩子输出: %s":                                  "Hook output: %s",
+	"运行前钩子执行失败，跳过本次运行: %v":                      "Pre-run hook failed, skipping this run: %v",
+	"运行后钩子执行失败: %v":                             "Post-run hook failed: %v",
+	"已开始监听 Docker 容器事件":                         "Watching Docker container events",
+	"webhook 服务已启动，监听地址: %s":                    "Webhook server started, listening on %s",
+	"webhook 服务运行失败，正在退出: %v":                   "Webhook server failed, shutting down: %v",
+	"未设置 --webhook-secret，webhook 接口不校验请求来源":    "--webhook-secret is not set, webhook requests are not authenticated",
+	"收到镜像 %s 的更新 webhook":                       "Received update webhook for image %s",
+	"--webhook-listen 仅在守护模式下生效，--once 模式下将被忽略": "--webhook-listen only works in daemon mode and is ignored with --once",
+	"容器 %s 已启动，开始检查镜像更新":                        "Container %s started, checking for image updates",
+	"Docker 服务不可用，%v 后第 %d 次重连: %v":             "Docker is unavailable, reconnect attempt %[2]d in %[1]v: %[3]v",
+	"已重新连接到 Docker 服务":                          "Reconnected to Docker",
+	"Docker 服务仍不可用，稍后重试: %v":                    "Docker is still unavailable, will retry later: %v",
+	"Docker 服务不可用，跳过本次检查: %v":                   "Docker is unavailable, skipping this check: %v",
+	"Docker 事件流中断，%v 后重新订阅: %v":                 "Docker event stream interrupted, resubscribing in %v: %v",
+	"创建事件监听器失败: %v":                             "Failed to create event watcher: %v",
+	"--watch-events 仅在守护模式下生效，--once 模式下将被忽略":   "--watch-events only works in daemon mode and is ignored with --once",
+	"运行超过 %v 的时间限制，已取消所有进行中的检查和更新":              "Run exceeded the %v time limit, cancelled all in-progress checks and updates",
+	"收到退出信号，已取消进行中的检查和更新":                       "Received shutdown signal, cancelled in-progress checks and updates",
+	"收到退出信号，正在停止定时任务":                           "Received shutdown signal, stopping the scheduler",
+	"定时任务已停止":                                   "Scheduler stopped",
+	"定时任务已启动，cron 表达式: %s":                      "Scheduler started, cron expression: %s",
+	"按 Ctrl+C 停止定时任务":                           "Press Ctrl+C to stop the scheduler",
+	"无效的 cron 表达式 '%s': %v":                     "Invalid cron expression '%s': %v",
+	"写入 Markdown 报告失败: %v":                      "Failed to write Markdown report: %v",
+	"导出 CSV 检查结果失败: %v":                         "Failed to export CSV results: %v",
+	"写入检查结果报告失败: %v":                            "Failed to write check report: %v",
+	"推送配置有误: %v":                                "Invalid notification config: %v",
+	"测试通知已发送":                                   "Test notification sent",
+	"自我更新失败: %v":                                "Self-update failed: %v",
+	"检查进度: %d/%d":                               "Progress: %d/%d",
+	"[%d/%d] 镜像 %-20s %s":                       "[%d/%d] Image %-20s %s",
+
+	// 输出
+	"✅ 最新":                               "✅ Up to date",
+	"❔ 无法确认":                             "❔ Unknown",
+	"❌ 失败":                               "❌ Failed",
+	"🔄 有新版本 %s":                          "🔄 New version %s",
+	"🔀 %d 个容器待切换":                        "🔀 %d container(s) to switch",
+	"🔄 有更新":                              "🔄 Update available",
+	"📥 已拉取":                              "📥 Pulled",
+	"📌 已固定":                              "📌 Pinned",
+	"🏠 本地镜像":                             "🏠 Local only",
+	"⏳ 等待稳定":                             "⏳ Too new",
+	"⏭️ 已忽略":                             "⏭️ Ignored",
+	"=== 容器列表 ===":                       "=== Containers ===",
+	"=== 镜像列表 ===":                       "=== Images ===",
+	"没有需要关注的容器":                          "No containers need attention",
+	"名称":                                 "Name",
+	"镜像":                                 "Image",
+	"状态":                                 "State",
+	"=== 统计信息 ===":                       "=== Summary ===",
+	"匹配的容器数: %d\n":                       "Matched containers: %d\n",
+	"检查的镜像数: %d\n":                       "Checked images: %d\n",
+	"有更新的镜像: %d\n":                       "Updated images: %d\n",
+	"最新的镜像: %d\n":                        "Up-to-date images: %d\n",
+	"跳过的镜像: %d\n":                        "Skipped images: %d\n",
+	"无法确认的镜像: %d\n":                      "Unknown images: %d\n",
+	"检查失败的镜像: %d\n":                      "Failed images: %d\n",
+	"检查耗时: %v\n":                         "Duration: %v\n",
+	"\n=== Docker 主机: %s ===\n":          "\n=== Docker host: %s ===\n",
+	"\nDocker 主机: %s":                    "\nDocker host: %s",
+	"\n=== 更新信息 ===\n":                   "\n=== Updates ===\n",
+	"容器 %-20s 更新成功✅ (%s)":                "Container %-20s updated ✅ (%s)",
+	"容器 %-20s 跳过更新⏭️: %s":                "Container %-20s update skipped ⏭️: %s",
+	"容器 %-20s 更新失败❌: %s":                 "Container %-20s update failed ❌: %s",
+	"=== 更新结果 ===":                       "=== Update results ===",
+	"镜像 %-20s 无法确认更新❔: %s\n":             "Image %-20s update unknown ❔: %s\n",
+	"镜像 %-20s 更新失败❌: %s\n":               "Image %-20s update failed ❌: %s\n",
+	"      WatchDucker - Docker 镜像更新检查器": "      WatchDucker - Docker image update checker",
+	"WatchDucker 检查报告":                   "WatchDucker check report",
+	"生成时间: %s\n":                         "Generated at: %s\n",
+	"## Docker 主机: %s\n\n":               "## Docker host: %s\n\n",
+	"❌ 主机处理失败: %s\n\n":                   "❌ Host failed: %s\n\n",
+	"容器":                                 "Container",
+	"旧 hash":                             "Old hash",
+	"新 hash":                             "New hash",
+	"本地 hash":                            "Local hash",
+	"远程 hash":                            "Remote hash",
+	"主机":                                 "Host",
+	"检查时间":                               "Checked at",
+	"\n更新 %d，最新 %d，跳过 %d，未知 %d，失败 %d，耗时 %v\n": "\n%d updated, %d up to date, %d skipped, %d unknown, %d failed, took %v\n",
+}
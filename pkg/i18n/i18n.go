@@ -0,0 +1,33 @@
+package i18n
+
+import "strings"
+
+// 支持的语言
+const (
+	ZH = "zh"
+	EN = "en"
+)
+
+// lang 当前输出语言，默认中文
+var lang = ZH
+
+// SetLang 设置输出语言 (zh/en)，无法识别时使用中文
+func SetLang(l string) {
+	switch strings.ToLower(strings.TrimSpace(l)) {
+	case EN:
+		lang = EN
+	default:
+		lang = ZH
+	}
+}
+
+// T 返回消息在当前语言下的文本，消息表中未收录的消息原样返回
+func T(msg string) string {
+	if lang != EN {
+		return msg
+	}
+	if translated, ok := en[msg]; ok {
+		return translated
+	}
+	return msg
+}
@@ -1,10 +1,15 @@
 package logger
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
+	"runtime/debug"
+	"strings"
 	"time"
+
+	"watchducker/pkg/i18n"
 )
 
 // Level 定义日志级别
@@ -33,10 +38,40 @@ var (
 	resetColor = "\033[0m"
 )
 
+// Format 定义日志输出格式
+type Format int
+
+const (
+	TEXT Format = iota
+	JSON
+)
+
+// jsonEntry JSON 格式的单条日志
+type jsonEntry struct {
+	Time  string `json:"time"`
+	Level string `json:"level"`
+	Msg   string `json:"msg"`
+}
+
+// Colored 只在标准输出的文本日志中着色的日志参数，写入日志文件和 JSON 日志时输出原文
+type Colored struct {
+	Text  string
+	Color string // ANSI 颜色控制符，如 "\033[33m"
+}
+
+// String 返回不带颜色控制符的原文
+func (c Colored) String() string {
+	return c.Text
+}
+
 // Logger 日志记录器
 type Logger struct {
 	level  Level
+	format Format
+	color  bool
 	output io.Writer
+	file   io.Writer
+	loc    *time.Location
 	prefix string
 }
 
@@ -44,7 +79,9 @@ type Logger struct {
 func New() *Logger {
 	return &Logger{
 		level:  INFO,
+		color:  true,
 		output: os.Stdout,
+		loc:    time.Local,
 		prefix: "",
 	}
 }
@@ -55,18 +92,58 @@ func (l *Logger) log(level Level, format string, args ...interface{}) {
 		return
 	}
 
-	timestamp := time.Now().Format(time.DateTime)
+	timestamp := time.Now().In(l.loc).Format(time.DateTime)
 	levelName := levelNames[level]
-	color := levelColors[level]
+	color, reset := levelColors[level], resetColor
+	if !l.color {
+		color, reset = "", ""
+	}
 
-	// 构建日志消息
-	message := fmt.Sprintf(format, args...)
+	// 构建日志消息，按当前语言翻译消息模板
+	message := fmt.Sprintf(i18n.T(format), args...)
+
+	if l.format == JSON {
+		line, err := json.Marshal(jsonEntry{Time: timestamp, Level: levelName, Msg: message})
+		if err != nil {
+			return
+		}
+		line = append(line, '\n')
+		if l.output != nil {
+			l.output.Write(line)
+		}
+		if l.file != nil {
+			l.file.Write(line)
+		}
+		return
+	}
 
 	// 格式化输出
-	logLine := fmt.Sprintf("%s%s [%-5s] %s%s\n",
-		timestamp, color, levelName, message, resetColor)
+	if l.output != nil {
+		stdoutMessage := message
+		if l.color {
+			stdoutMessage = fmt.Sprintf(i18n.T(format), colorArgs(args, color)...)
+		}
+		logLine := fmt.Sprintf("%s%s [%-5s] %s%s\n",
+			timestamp, color, levelName, stdoutMessage, reset)
+		fmt.Fprint(l.output, logLine)
+	}
+
+	// 日志文件不写入颜色控制符
+	if l.file != nil {
+		fmt.Fprintf(l.file, "%s [%-5s] %s\n", timestamp, levelName, message)
+	}
+}
 
-	fmt.Fprint(l.output, logLine)
+// colorArgs 将 Colored 参数替换为带颜色控制符的文本，着色结束后恢复为整行的级别颜色
+func colorArgs(args []interface{}, lineColor string) []interface{} {
+	colored := make([]interface{}, len(args))
+	for i, arg := range args {
+		if c, ok := arg.(Colored); ok && c.Color != "" {
+			arg = c.Color + c.Text + resetColor + lineColor
+		}
+		colored[i] = arg
+	}
+	return colored
 }
 
 // Debug 输出调试日志
@@ -123,6 +200,13 @@ func Fatal(format string, args ...interface{}) {
 	defaultLogger.Fatal(format, args...)
 }
 
+// Recover 恢复当前 goroutine 中的 panic 并记录错误和调用栈，避免守护进程崩溃，需直接通过 defer 调用
+func Recover() {
+	if r := recover(); r != nil {
+		defaultLogger.Error("已从 panic 中恢复: %v\n%s", r, debug.Stack())
+	}
+}
+
 // SetLevel 设置全局日志级别
 func SetLevel(levelStr string) {
 	switch levelStr {
@@ -136,3 +220,37 @@ func SetLevel(levelStr string) {
 		defaultLogger.level = INFO
 	}
 }
+
+// SetColor 设置是否在标准输出中使用颜色
+func SetColor(enabled bool) {
+	defaultLogger.color = enabled
+}
+
+// SetLocation 设置日志时间戳使用的时区
+func SetLocation(loc *time.Location) {
+	defaultLogger.loc = loc
+}
+
+// SetFormat 设置全局日志输出格式 (text/json)
+func SetFormat(formatStr string) {
+	switch strings.ToLower(formatStr) {
+	case "json":
+		defaultLogger.format = JSON
+	default:
+		defaultLogger.format = TEXT
+	}
+}
+
+// SetFile 设置日志文件输出，maxSizeMB 为单个文件大小上限（MB），maxBackups 为保留的滚动文件数
+func SetFile(path string, maxSizeMB, maxBackups int, keepStdout bool) error {
+	w, err := newRotateWriter(path, int64(maxSizeMB)*1024*1024, maxBackups)
+	if err != nil {
+		return err
+	}
+
+	defaultLogger.file = w
+	if !keepStdout {
+		defaultLogger.output = nil
+	}
+	return nil
+}
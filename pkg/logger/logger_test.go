@@ -0,0 +1,48 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestColoredOnlyOnStdout(t *testing.T) {
+	var stdout, file bytes.Buffer
+	l := New()
+	l.output = &stdout
+	l.file = &file
+
+	l.Info("镜像 %s %s", "nginx:latest", Colored{Text: "有更新", Color: "\033[33m"})
+
+	if !strings.Contains(stdout.String(), "\033[33m有更新"+resetColor) {
+		t.Errorf("标准输出应包含着色的状态: %q", stdout.String())
+	}
+	if strings.Contains(file.String(), "\033[") {
+		t.Errorf("日志文件不应包含颜色控制符: %q", file.String())
+	}
+	if !strings.Contains(file.String(), "镜像 nginx:latest 有更新") {
+		t.Errorf("日志文件内容 = %q", file.String())
+	}
+
+	// 关闭颜色时标准输出同样不带控制符
+	stdout.Reset()
+	l.color = false
+	l.Info("镜像 %s %s", "nginx:latest", Colored{Text: "有更新", Color: "\033[33m"})
+	if strings.Contains(stdout.String(), "\033[") {
+		t.Errorf("关闭颜色后标准输出不应包含颜色控制符: %q", stdout.String())
+	}
+
+	// JSON 格式的 msg 字段保持原文
+	stdout.Reset()
+	l.color = true
+	l.format = JSON
+	l.Info("镜像 %s %s", "nginx:latest", Colored{Text: "有更新", Color: "\033[33m"})
+	var entry jsonEntry
+	if err := json.Unmarshal(stdout.Bytes(), &entry); err != nil {
+		t.Fatalf("解析 JSON 日志失败: %v", err)
+	}
+	if entry.Msg != "镜像 nginx:latest 有更新" {
+		t.Errorf("JSON msg = %q", entry.Msg)
+	}
+}
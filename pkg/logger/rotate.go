@@ -0,0 +1,113 @@
+package logger
+
+import (
+	"fmt"
+	"os"
+	"sync"
+)
+
+// rotateWriter 基于文件大小滚动的日志文件写入器
+type rotateWriter struct {
+	mu         sync.Mutex
+	path       string
+	maxSize    int64
+	maxBackups int
+	file       *os.File
+	size       int64
+
+	rotateFailed bool // 上次滚动是否失败，用于只提示一次
+}
+
+// newRotateWriter 创建日志文件写入器，maxSize 为单个文件的最大字节数
+func newRotateWriter(path string, maxSize int64, maxBackups int) (*rotateWriter, error) {
+	w := &rotateWriter{
+		path:       path,
+		maxSize:    maxSize,
+		maxBackups: maxBackups,
+	}
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// open 以追加模式打开日志文件
+func (w *rotateWriter) open() error {
+	file, size, err := openLogFile(w.path)
+	if err != nil {
+		return err
+	}
+
+	w.file = file
+	w.size = size
+	return nil
+}
+
+// openLogFile 以追加模式打开日志文件，返回文件和当前大小
+func openLogFile(path string) (*os.File, int64, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, 0, fmt.Errorf("打开日志文件失败: %w", err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, 0, fmt.Errorf("获取日志文件信息失败: %w", err)
+	}
+	return file, info.Size(), nil
+}
+
+// Write 写入日志，超过大小上限时先滚动文件；滚动失败时继续写入当前文件，下次写入时重试
+func (w *rotateWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.maxSize > 0 && w.size+int64(len(p)) > w.maxSize && w.size > 0 {
+		if err := w.rotate(); err != nil {
+			// 只在首次失败时提示，避免每条日志都输出一次
+			if !w.rotateFailed {
+				fmt.Fprintf(os.Stderr, "日志文件滚动失败，继续写入当前文件: %v\n", err)
+			}
+			w.rotateFailed = true
+		} else {
+			w.rotateFailed = false
+		}
+	}
+
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// rotate 将 path 依次重命名为 path.1、path.2 ...，并丢弃超出保留数量的旧文件。
+// 先重命名再打开新文件，成功后才关闭旧文件，任一步骤失败时当前文件仍可继续写入
+func (w *rotateWriter) rotate() error {
+	if w.maxBackups > 0 {
+		// 先把当前文件移开，失败时不改动已有的备份，避免每次重试都挤掉一个备份
+		rotating := w.path + ".rotating"
+		if err := os.Rename(w.path, rotating); err != nil {
+			return fmt.Errorf("滚动日志文件失败: %w", err)
+		}
+		os.Remove(fmt.Sprintf("%s.%d", w.path, w.maxBackups))
+		for i := w.maxBackups - 1; i > 0; i-- {
+			os.Rename(fmt.Sprintf("%s.%d", w.path, i), fmt.Sprintf("%s.%d", w.path, i+1))
+		}
+		if err := os.Rename(rotating, w.path+".1"); err != nil {
+			os.Rename(rotating, w.path)
+			return fmt.Errorf("滚动日志文件失败: %w", err)
+		}
+	} else if err := os.Remove(w.path); err != nil {
+		return fmt.Errorf("删除日志文件失败: %w", err)
+	}
+
+	// 旧文件已改名或删除，打开失败时继续写入旧文件句柄
+	file, size, err := openLogFile(w.path)
+	if err != nil {
+		return err
+	}
+	w.file.Close()
+	w.file = file
+	w.size = size
+	return nil
+}
@@ -0,0 +1,56 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRotateWriterKeepsWritingWhenRotateFails(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "watchducker.log")
+	w, err := newRotateWriter(path, 10, 2)
+	if err != nil {
+		t.Fatalf("创建日志文件写入器失败: %v", err)
+	}
+	defer w.file.Close()
+
+	// 已存在的非空目录使滚动时的重命名失败
+	blocker := path + ".rotating"
+	if err := os.MkdirAll(filepath.Join(blocker, "x"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, line := range []string{"first\n", "second\n", "third\n"} {
+		if _, err := w.Write([]byte(line)); err != nil {
+			t.Fatalf("滚动失败后写入返回错误: %v", err)
+		}
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "first\nsecond\nthird\n" {
+		t.Errorf("滚动失败时日志应继续追加到当前文件，得到 %q", data)
+	}
+	if _, err := os.Stat(path + ".1"); !os.IsNotExist(err) {
+		t.Errorf("滚动失败时不应产生备份文件: %v", err)
+	}
+
+	// 故障消除后下次写入时恢复滚动
+	if err := os.RemoveAll(blocker); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write([]byte("fourth\n")); err != nil {
+		t.Fatalf("写入失败: %v", err)
+	}
+	backup, err := os.ReadFile(path + ".1")
+	if err != nil {
+		t.Fatalf("恢复后应完成滚动: %v", err)
+	}
+	if string(backup) != "first\nsecond\nthird\n" {
+		t.Errorf("备份文件内容 = %q", backup)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "fourth\n" {
+		t.Errorf("新日志文件内容 = %q, want %q", data, "fourth\n")
+	}
+}
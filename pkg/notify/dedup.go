@@ -0,0 +1,52 @@
+package notify
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+// dedup 记录最近推送过的消息指纹，用于在去重窗口内跳过重复推送
+var dedup = struct {
+	sync.Mutex
+	window time.Duration
+	sent   map[string]time.Time
+}{sent: make(map[string]time.Time)}
+
+// SetDedupWindow 设置通知去重窗口，0 表示不去重
+func SetDedupWindow(window time.Duration) {
+	dedup.Lock()
+	defer dedup.Unlock()
+	dedup.window = window
+}
+
+// fingerprint 计算消息内容的指纹
+func fingerprint(title, msg string) string {
+	sum := sha256.Sum256([]byte(title + "\n" + msg))
+	return hex.EncodeToString(sum[:])
+}
+
+// markSent 记录本次推送，若相同内容在去重窗口内已推送过则返回 false
+func markSent(title, msg string) bool {
+	dedup.Lock()
+	defer dedup.Unlock()
+
+	if dedup.window <= 0 {
+		return true
+	}
+
+	now := time.Now()
+	for key, sentAt := range dedup.sent {
+		if now.Sub(sentAt) >= dedup.window {
+			delete(dedup.sent, key)
+		}
+	}
+
+	key := fingerprint(title, msg)
+	if _, ok := dedup.sent[key]; ok {
+		return false
+	}
+	dedup.sent[key] = now
+	return true
+}
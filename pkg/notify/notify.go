@@ -4,7 +4,10 @@ import (
 	"bytes"
 	"crypto/hmac"
 	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -14,6 +17,8 @@ import (
 	"net/url"
 	"os"
 	"reflect"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 	"watchducker/pkg/logger"
@@ -23,8 +28,12 @@ import (
 
 type Config struct {
 	Setting struct {
-		PushServer string `mapstructure:"push_server"`
-		LogLevel   string `mapstructure:"log_level"`
+		PushServer        string `mapstructure:"push_server"`
+		FailurePushServer string `mapstructure:"failure_push_server"` // 更新失败时额外告警的渠道列表
+		LogLevel          string `mapstructure:"log_level"`
+		CACert            string `mapstructure:"ca_cert"`
+		Proxy             string `mapstructure:"proxy"`   // 推送请求使用的代理，留空时读取 HTTP_PROXY/HTTPS_PROXY 环境变量
+		Timeout           int    `mapstructure:"timeout"` // 单次推送请求的超时时间（秒）
 	} `mapstructure:"setting"`
 
 	Telegram struct {
@@ -42,8 +51,10 @@ type Config struct {
 	} `mapstructure:"pushplus"`
 
 	Cqhttp struct {
-		URL string `mapstructure:"cqhttp_url"`
-		QQ  int    `mapstructure:"cqhttp_qq"`
+		URL         string `mapstructure:"cqhttp_url"`
+		QQ          int    `mapstructure:"cqhttp_qq"`
+		GroupID     int    `mapstructure:"group_id"`
+		MessageType string `mapstructure:"message_type"`
 	} `mapstructure:"cqhttp"`
 
 	Smtp struct {
@@ -63,8 +74,9 @@ type Config struct {
 	} `mapstructure:"wecom"`
 
 	WecomRobot struct {
-		URL    string `mapstructure:"url"`
-		Mobile string `mapstructure:"mobile"`
+		URL     string `mapstructure:"url"`
+		Mobile  string `mapstructure:"mobile"`  // 需要 @ 的手机号，逗号分隔多个
+		MsgType string `mapstructure:"msgtype"` // 消息类型 text/markdown，留空为 text
 	} `mapstructure:"wecomrobot"`
 
 	Pushdeer struct {
@@ -79,6 +91,8 @@ type Config struct {
 
 	Feishu struct {
 		Webhook string `mapstructure:"webhook"`
+		Secret  string `mapstructure:"secret"`
+		Card    bool   `mapstructure:"card"`
 	} `mapstructure:"feishubot"`
 
 	Bark struct {
@@ -98,17 +112,45 @@ type Config struct {
 	} `mapstructure:"ifttt"`
 
 	Webhook struct {
-		URL string `mapstructure:"webhook_url"`
+		URL    string `mapstructure:"webhook_url"`
+		Secret string `mapstructure:"secret"`
 	} `mapstructure:"webhook"`
 
 	Qmsg struct {
-		Key string `mapstructure:"key"`
+		APIURL string `mapstructure:"api_url"`
+		Key    string `mapstructure:"key"`
 	} `mapstructure:"qmsg"`
 
 	Discord struct {
 		Webhook   string `mapstructure:"webhook"`
 		VerifySSL bool   `mapstructure:"verify_ssl"`
+		Username  string `mapstructure:"username"`
+		AvatarURL string `mapstructure:"avatar_url"`
 	} `mapstructure:"discord"`
+
+	Line struct {
+		Token string `mapstructure:"token"`
+	} `mapstructure:"line"`
+
+	Twilio struct {
+		AccountSID string `mapstructure:"account_sid"`
+		AuthToken  string `mapstructure:"auth_token"`
+		From       string `mapstructure:"from"`
+		To         string `mapstructure:"to"`
+	} `mapstructure:"twilio"`
+
+	Sendgrid struct {
+		APIKey string `mapstructure:"api_key"`
+		From   string `mapstructure:"from"`
+		To     string `mapstructure:"to"`
+	} `mapstructure:"sendgrid"`
+
+	GitHub struct {
+		APIURL string `mapstructure:"api_url"` // GitHub Enterprise 等自建实例的 API 地址，默认 https://api.github.com
+		Token  string `mapstructure:"token"`
+		Repo   string `mapstructure:"repo"`  // 仓库，格式为 owner/name
+		Issue  int    `mapstructure:"issue"` // 接收评论的 issue 编号
+	} `mapstructure:"github"`
 }
 
 var cfg Config
@@ -139,6 +181,10 @@ func loadConfig(configPath string) error {
 		return fmt.Errorf("配置解析失败: %v", err)
 	}
 
+	if err := updateHTTPClient(cfg.Setting.CACert, cfg.Setting.Proxy, cfg.Setting.Timeout); err != nil {
+		return err
+	}
+
 	// 设置日志级别
 	if cfg.Setting.LogLevel != "" {
 		logger.SetLevel(cfg.Setting.LogLevel)
@@ -176,6 +222,81 @@ func bindStructFields(v *viper.Viper, prefix string, t reflect.Type) {
 }
 
 // ================== HTTP 工具 ==================
+
+// defaultPushTimeout 未配置 setting.timeout 时单次推送请求的超时时间
+const defaultPushTimeout = 30 * time.Second
+
+// httpClient 所有推送渠道共用的 HTTP 客户端，复用同一个连接池，避免每次推送都重新建立连接和 TLS 握手
+var httpClient = &http.Client{Timeout: defaultPushTimeout}
+
+// httpClientSettings 当前 httpClient 对应的 ca_cert、proxy 和 timeout，未按配置创建过时为 nil
+var httpClientSettings *clientSettings
+
+// clientSettings 创建推送 HTTP 客户端使用的配置
+type clientSettings struct {
+	caCert  string
+	proxy   string
+	timeout int
+}
+
+// updateHTTPClient 每次加载配置时调用，只在 ca_cert、proxy 或 timeout 变化时重建 httpClient，
+// 被替换的客户端关闭空闲连接，避免连接在空闲超时前一直保持
+func updateHTTPClient(caCert, proxy string, timeout int) error {
+	settings := clientSettings{caCert: caCert, proxy: proxy, timeout: timeout}
+	if httpClientSettings != nil && *httpClientSettings == settings {
+		return nil
+	}
+
+	client, err := newHTTPClient(caCert, proxy, timeout)
+	if err != nil {
+		return err
+	}
+
+	// 初始的客户端使用 http.DefaultTransport，与其他模块共用，不能关闭其连接
+	if httpClientSettings != nil {
+		httpClient.CloseIdleConnections()
+	}
+	httpClient = client
+	httpClientSettings = &settings
+	return nil
+}
+
+// newHTTPClient 创建推送使用的 HTTP 客户端：timeout 为单次请求超时（秒，不大于 0 时使用默认值）；
+// 配置了 proxy 时所有请求经该代理发出；配置了 caCert 时在系统证书之外额外信任该 CA，用于自托管服务的内网证书
+func newHTTPClient(caCert, proxy string, timeout int) (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	if proxy != "" {
+		proxyURL, err := url.Parse(proxy)
+		if err != nil || proxyURL.Host == "" {
+			return nil, fmt.Errorf("无效的推送代理地址: %s", proxy)
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	if caCert != "" {
+		pem, err := os.ReadFile(caCert)
+		if err != nil {
+			return nil, fmt.Errorf("读取 CA 证书失败: %w", err)
+		}
+
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("CA 证书 %s 中没有有效的 PEM 证书", caCert)
+		}
+		transport.TLSClientConfig = &tls.Config{RootCAs: pool}
+	}
+
+	clientTimeout := defaultPushTimeout
+	if timeout > 0 {
+		clientTimeout = time.Duration(timeout) * time.Second
+	}
+	return &http.Client{Transport: transport, Timeout: clientTimeout}, nil
+}
+
 func postJSON(url string, body interface{}) ([]byte, error) {
 	// 序列化请求体
 	js, err := json.Marshal(body)
@@ -183,7 +304,7 @@ func postJSON(url string, body interface{}) ([]byte, error) {
 		return nil, err
 	}
 	// 发送请求
-	resp, err := http.Post(url, "application/json", bytes.NewBuffer(js))
+	resp, err := httpClient.Post(url, "application/json", bytes.NewBuffer(js))
 	if err != nil {
 		return nil, err
 	}
@@ -200,7 +321,7 @@ func postJSON(url string, body interface{}) ([]byte, error) {
 
 func postForm(url string, data url.Values) ([]byte, error) {
 	// 发送请求
-	resp, err := http.PostForm(url, data)
+	resp, err := httpClient.PostForm(url, data)
 	if err != nil {
 		return nil, err
 	}
@@ -215,6 +336,98 @@ func postForm(url string, data url.Values) ([]byte, error) {
 	return responseBody, nil
 }
 
+// doRequest 发送自定义请求，响应状态码非 2xx 时返回错误
+func doRequest(req *http.Request) ([]byte, error) {
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	responseBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	logger.Debug("Received response from %s - Status: %d, Body: %s", req.URL.Host, resp.StatusCode, string(responseBody))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return responseBody, fmt.Errorf("状态码 %d", resp.StatusCode)
+	}
+	return responseBody, nil
+}
+
+// apiGet 发送 GET 请求并检查响应，用于 PushDeer、Bark 等通过 URL 传参的推送服务
+func apiGet(url string) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return apiDo(req)
+}
+
+// apiPostForm 发送表单 POST 请求并检查响应
+func apiPostForm(url string, data url.Values) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodPost, url, strings.NewReader(data.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return apiDo(req)
+}
+
+// apiDo 发送请求，状态码和响应中的错误信息都视为失败
+func apiDo(req *http.Request) ([]byte, error) {
+	responseBody, err := doRequest(req)
+	if err != nil {
+		return responseBody, err
+	}
+	return responseBody, checkAPIResponse(responseBody)
+}
+
+// checkAPIResponse 解析推送服务返回的 JSON，识别 error 字段、success=false 或非成功的 code，
+// 这些服务在参数错误（如 key 无效）时仍可能返回 200 状态码
+func checkAPIResponse(body []byte) error {
+	var resp struct {
+		Code    *int   `json:"code"`
+		Success *bool  `json:"success"`
+		Error   string `json:"error"`
+		Message string `json:"message"`
+		Reason  string `json:"reason"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		// 非 JSON 响应无法判断结果，以状态码为准
+		return nil
+	}
+
+	detail := resp.Error
+	if detail == "" {
+		detail = resp.Reason
+	}
+	if detail == "" {
+		detail = resp.Message
+	}
+
+	if resp.Error != "" {
+		return fmt.Errorf("服务返回错误: %s", detail)
+	}
+	if resp.Success != nil && !*resp.Success {
+		return fmt.Errorf("服务返回失败: %s", detail)
+	}
+	if resp.Code != nil && *resp.Code != 0 && *resp.Code != http.StatusOK {
+		return fmt.Errorf("服务返回错误码 %d: %s", *resp.Code, detail)
+	}
+	return nil
+}
+
+// truncate 将消息截断到指定字符数以内，超长时以省略号结尾
+func truncate(s string, limit int) string {
+	runes := []rune(s)
+	if len(runes) <= limit {
+		return s
+	}
+	return string(runes[:limit-1]) + "…"
+}
+
 // ================== 推送模块 ==================
 func telegram(title, msg string) {
 	api := cfg.Telegram.APIURL
@@ -255,10 +468,16 @@ func pushplus(title, msg string) {
 }
 
 func cqhttp(title, msg string) {
-	url := cfg.Cqhttp.URL
-	user := cfg.Cqhttp.QQ
-	body := map[string]interface{}{"user_id": user, "message": title + "\n" + msg}
-	_, err := postJSON(url, body)
+	s := cfg.Cqhttp
+	body := map[string]interface{}{"message": title + "\n" + msg}
+	if cqhttpToGroup() {
+		body["message_type"] = "group"
+		body["group_id"] = s.GroupID
+	} else {
+		body["message_type"] = "private"
+		body["user_id"] = s.QQ
+	}
+	_, err := postJSON(s.URL, body)
 	if err != nil {
 		logger.Error("CQHTTP 失败: %v", err)
 		return
@@ -266,6 +485,14 @@ func cqhttp(title, msg string) {
 	logger.Info("CQHTTP 成功")
 }
 
+// cqhttpToGroup 判断 cqhttp 是否推送到群：message_type 指定时以其为准，否则配置了 group_id 时推送到群、未配置时私聊 cqhttp_qq
+func cqhttpToGroup() bool {
+	if cfg.Cqhttp.MessageType != "" {
+		return cfg.Cqhttp.MessageType == "group"
+	}
+	return cfg.Cqhttp.GroupID != 0
+}
+
 func smtpSend(title, msg string) {
 	s := cfg.Smtp
 	m := fmt.Sprintf("To: %s\r\nSubject: %s\r\n\r\n%s", s.ToAddr, title, msg)
@@ -281,7 +508,7 @@ func smtpSend(title, msg string) {
 
 func wecom(title, msg string) {
 	s := cfg.Wecom
-	tokenResp, err := http.Get(fmt.Sprintf("https://qyapi.weixin.qq.com/cgi-bin/gettoken?corpid=%s&corpsecret=%s", s.WechatID, s.Secret))
+	tokenResp, err := httpClient.Get(fmt.Sprintf("https://qyapi.weixin.qq.com/cgi-bin/gettoken?corpid=%s&corpsecret=%s", s.WechatID, s.Secret))
 	if err != nil {
 		logger.Error("WeCom 获取token失败: %v", err)
 		return
@@ -311,21 +538,66 @@ func wecom(title, msg string) {
 
 func wecomRobot(title, msg string) {
 	s := cfg.WecomRobot
-	body := map[string]interface{}{
-		"msgtype": "text",
-		"text": map[string]interface{}{
-			"content":               title + "\n" + msg,
-			"mentioned_mobile_list": []string{s.Mobile},
-		},
+	mobiles := splitList(s.Mobile)
+
+	var body map[string]interface{}
+	if s.MsgType == "markdown" {
+		body = map[string]interface{}{
+			"msgtype":  "markdown",
+			"markdown": map[string]string{"content": wecomMarkdown(title, msg)},
+		}
+	} else {
+		body = map[string]interface{}{
+			"msgtype": "text",
+			"text": map[string]interface{}{
+				"content":               title + "\n" + msg,
+				"mentioned_mobile_list": mobiles,
+			},
+		}
 	}
 	_, err := postJSON(s.URL, body)
 	if err != nil {
 		logger.Error("WeCom机器人 失败: %v", err)
 		return
 	}
+
+	// markdown 消息不支持按手机号 @，需要提醒时单独发送一条 @ 消息
+	if s.MsgType == "markdown" && len(mobiles) > 0 {
+		mention := map[string]interface{}{
+			"msgtype": "text",
+			"text": map[string]interface{}{
+				"content":               title,
+				"mentioned_mobile_list": mobiles,
+			},
+		}
+		if _, err := postJSON(s.URL, mention); err != nil {
+			logger.Warn("WeCom机器人 @ 提醒发送失败: %v", err)
+		}
+	}
 	logger.Info("WeCom机器人 成功")
 }
 
+// wecomMarkdown 生成企业微信机器人的 markdown 内容，分节标题加粗，失败行标为橙红色，成功行标为绿色
+func wecomMarkdown(title, msg string) string {
+	var b strings.Builder
+	b.WriteString("## " + title + "\n")
+	for _, line := range strings.Split(msg, "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == "":
+			continue
+		case strings.HasPrefix(trimmed, "===") && strings.HasSuffix(trimmed, "==="):
+			line = "**" + strings.TrimSpace(strings.Trim(trimmed, "=")) + "**"
+		case strings.Contains(trimmed, "❌"):
+			line = `<font color="warning">` + line + `</font>`
+		case strings.Contains(trimmed, "✅"):
+			line = `<font color="info">` + line + `</font>`
+		}
+		b.WriteString(line + "\n")
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
 func pushdeer(title, msg string) {
 	s := cfg.Pushdeer
 	params := url.Values{
@@ -335,7 +607,7 @@ func pushdeer(title, msg string) {
 		"type":    {"markdown"},
 	}
 	full := fmt.Sprintf("%s/message/push?%s", s.APIURL, params.Encode())
-	_, err := http.Get(full)
+	_, err := apiGet(full)
 	if err != nil {
 		logger.Error("PushDeer 失败: %v", err)
 		return
@@ -343,15 +615,20 @@ func pushdeer(title, msg string) {
 	logger.Info("PushDeer 成功")
 }
 
+// hmacBase64 计算 HMAC-SHA256 并以 base64 编码，用于钉钉和飞书机器人的加签
+func hmacBase64(key, data []byte) string {
+	h := hmac.New(sha256.New, key)
+	h.Write(data)
+	return base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
+
 func dingrobot(title, msg string) {
 	s := cfg.Dingrobot
 	api := s.Webhook
 	if s.Secret != "" {
 		timestamp := fmt.Sprintf("%d", time.Now().UnixNano()/1e6)
 		stringToSign := fmt.Sprintf("%s\n%s", timestamp, s.Secret)
-		h := hmac.New(sha256.New, []byte(s.Secret))
-		h.Write([]byte(stringToSign))
-		sign := url.QueryEscape(base64.StdEncoding.EncodeToString(h.Sum(nil)))
+		sign := url.QueryEscape(hmacBase64([]byte(s.Secret), []byte(stringToSign)))
 		api = fmt.Sprintf("%s&timestamp=%s&sign=%s", api, timestamp, sign)
 	}
 	body := map[string]interface{}{
@@ -372,6 +649,18 @@ func feishu(title, msg string) {
 		"msg_type": "text",
 		"content":  map[string]string{"text": title + "\n" + msg},
 	}
+	if cfg.Feishu.Card {
+		body = map[string]interface{}{
+			"msg_type": "interactive",
+			"card":     feishuCard(title, msg),
+		}
+	}
+	// 飞书加签以 "timestamp\nsecret" 作为密钥对空内容计算签名，时间戳单位为秒
+	if cfg.Feishu.Secret != "" {
+		timestamp := fmt.Sprintf("%d", time.Now().Unix())
+		body["timestamp"] = timestamp
+		body["sign"] = hmacBase64([]byte(timestamp+"\n"+cfg.Feishu.Secret), nil)
+	}
 	_, err := postJSON(api, body)
 	if err != nil {
 		logger.Error("飞书 失败: %v", err)
@@ -380,12 +669,45 @@ func feishu(title, msg string) {
 	logger.Info("飞书 成功")
 }
 
+// feishuCard 生成飞书卡片消息，标题按是否包含失败着色，每行内容作为一个字段
+func feishuCard(title, msg string) map[string]interface{} {
+	template := "green"
+	if strings.Contains(title, "失败") || strings.Contains(msg, "❌") {
+		template = "red"
+	}
+
+	var elements []map[string]interface{}
+	for _, line := range strings.Split(msg, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		// "=== 更新信息 ===" 形式的分节标题加粗显示
+		if strings.HasPrefix(line, "===") && strings.HasSuffix(line, "===") {
+			line = "**" + strings.TrimSpace(strings.Trim(line, "=")) + "**"
+		}
+		elements = append(elements, map[string]interface{}{
+			"tag":  "div",
+			"text": map[string]string{"tag": "lark_md", "content": line},
+		})
+	}
+
+	return map[string]interface{}{
+		"config": map[string]bool{"wide_screen_mode": true},
+		"header": map[string]interface{}{
+			"title":    map[string]string{"tag": "plain_text", "content": title},
+			"template": template,
+		},
+		"elements": elements,
+	}
+}
+
 func bark(title, msg string) {
 	s := cfg.Bark
 	t := url.QueryEscape(title)
 	m := url.QueryEscape(msg)
 	full := fmt.Sprintf("%s/%s/%s/%s", s.APIURL, s.Token, t, m)
-	_, err := http.Get(full)
+	_, err := apiGet(full)
 	if err != nil {
 		logger.Error("Bark 失败: %v", err)
 		return
@@ -420,20 +742,42 @@ func ifttt(title, msg string) {
 }
 
 func webhook(title, msg string) {
-	api := cfg.Webhook.URL
+	s := cfg.Webhook
 	body := map[string]string{"title": title, "message": msg}
-	_, err := postJSON(api, body)
+	js, err := json.Marshal(body)
+	if err != nil {
+		logger.Error("Webhook 失败: %v", err)
+		return
+	}
+	req, err := http.NewRequest(http.MethodPost, s.URL, bytes.NewReader(js))
 	if err != nil {
 		logger.Error("Webhook 失败: %v", err)
 		return
 	}
+	req.Header.Set("Content-Type", "application/json")
+
+	// 配置了密钥时对请求体签名，格式同 GitHub webhook: sha256=<hex>
+	if s.Secret != "" {
+		h := hmac.New(sha256.New, []byte(s.Secret))
+		h.Write(js)
+		req.Header.Set("X-WatchDucker-Signature", "sha256="+hex.EncodeToString(h.Sum(nil)))
+	}
+
+	if _, err := doRequest(req); err != nil {
+		logger.Error("Webhook 失败: %v", err)
+		return
+	}
 	logger.Info("Webhook 成功")
 }
 
 func qmsg(title, msg string) {
-	key := cfg.Qmsg.Key
+	s := cfg.Qmsg
+	api := s.APIURL
+	if api == "" {
+		api = "https://qmsg.zendee.cn"
+	}
 	data := url.Values{"msg": {title + "\n" + msg}}
-	_, err := postForm(fmt.Sprintf("https://qmsg.zendee.cn/send/%s", key), data)
+	_, err := apiPostForm(fmt.Sprintf("%s/send/%s", strings.TrimSuffix(api, "/"), s.Key), data)
 	if err != nil {
 		logger.Error("Qmsg 失败: %v", err)
 		return
@@ -443,8 +787,12 @@ func qmsg(title, msg string) {
 
 func discord(title, msg string) {
 	s := cfg.Discord
+	username := s.Username
+	if username == "" {
+		username = "WatchDucker"
+	}
 	body := map[string]interface{}{
-		"username": "Kuro-autosignin",
+		"username": username,
 		"embeds": []map[string]interface{}{
 			{
 				"title":       title,
@@ -454,6 +802,9 @@ func discord(title, msg string) {
 			},
 		},
 	}
+	if s.AvatarURL != "" {
+		body["avatar_url"] = s.AvatarURL
+	}
 	_, err := postJSON(s.Webhook, body)
 	if err != nil {
 		logger.Error("Discord 失败: %v", err)
@@ -462,59 +813,322 @@ func discord(title, msg string) {
 	logger.Info("Discord 成功")
 }
 
+func line(title, msg string) {
+	data := url.Values{"message": {title + "\n" + msg}}
+	req, err := http.NewRequest(http.MethodPost, "https://notify-api.line.me/api/notify", strings.NewReader(data.Encode()))
+	if err != nil {
+		logger.Error("LINE 失败: %v", err)
+		return
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Authorization", "Bearer "+cfg.Line.Token)
+
+	if _, err := doRequest(req); err != nil {
+		logger.Error("LINE 失败: %v", err)
+		return
+	}
+	logger.Info("LINE 成功")
+}
+
+func twilio(title, msg string) {
+	s := cfg.Twilio
+	data := url.Values{
+		"From": {s.From},
+		"To":   {s.To},
+		"Body": {truncate(title+"\n"+msg, 160)},
+	}
+	api := fmt.Sprintf("https://api.twilio.com/2010-04-01/Accounts/%s/Messages.json", s.AccountSID)
+	req, err := http.NewRequest(http.MethodPost, api, strings.NewReader(data.Encode()))
+	if err != nil {
+		logger.Error("Twilio 失败: %v", err)
+		return
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(s.AccountSID, s.AuthToken)
+
+	if _, err := doRequest(req); err != nil {
+		logger.Error("Twilio 失败: %v", err)
+		return
+	}
+	logger.Info("Twilio 成功")
+}
+
+func sendgrid(title, msg string) {
+	s := cfg.Sendgrid
+	body := map[string]interface{}{
+		"personalizations": []map[string]interface{}{
+			{"to": []map[string]string{{"email": s.To}}},
+		},
+		"from":    map[string]string{"email": s.From},
+		"subject": title,
+		"content": []map[string]string{{"type": "text/plain", "value": msg}},
+	}
+	js, err := json.Marshal(body)
+	if err != nil {
+		logger.Error("SendGrid 失败: %v", err)
+		return
+	}
+	req, err := http.NewRequest(http.MethodPost, "https://api.sendgrid.com/v3/mail/send", bytes.NewReader(js))
+	if err != nil {
+		logger.Error("SendGrid 失败: %v", err)
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+s.APIKey)
+
+	if _, err := doRequest(req); err != nil {
+		logger.Error("SendGrid 失败: %v", err)
+		return
+	}
+	logger.Info("SendGrid 成功")
+}
+
+// github 将消息作为评论发到指定的 GitHub issue，便于在仓库中沉淀更新历史
+func github(title, msg string) {
+	s := cfg.GitHub
+	api := s.APIURL
+	if api == "" {
+		api = "https://api.github.com"
+	}
+
+	js, err := json.Marshal(map[string]string{
+		"body": fmt.Sprintf("### %s\n\n```\n%s\n```", title, strings.TrimSpace(msg)),
+	})
+	if err != nil {
+		logger.Error("GitHub 失败: %v", err)
+		return
+	}
+
+	endpoint := fmt.Sprintf("%s/repos/%s/issues/%d/comments", strings.TrimSuffix(api, "/"), s.Repo, s.Issue)
+	req, err := http.NewRequest(http.MethodPost, endpoint, bytes.NewReader(js))
+	if err != nil {
+		logger.Error("GitHub 失败: %v", err)
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Authorization", "Bearer "+s.Token)
+
+	if _, err := doRequest(req); err != nil {
+		logger.Error("GitHub 失败: %v", err)
+		return
+	}
+	logger.Info("GitHub 成功")
+}
+
+// ================== 渠道注册 ==================
+
+// channel 推送渠道
+type channel struct {
+	send     func(title, msg string)
+	required func() map[string]string // 必填配置项 -> 当前值
+}
+
+var channels = map[string]channel{
+	"telegram": {telegram, func() map[string]string {
+		return map[string]string{"telegram.api_url": cfg.Telegram.APIURL, "telegram.bot_token": cfg.Telegram.BotToken, "telegram.chat_id": cfg.Telegram.ChatID}
+	}},
+	"ftqq": {ftqq, func() map[string]string {
+		return map[string]string{"ftqq.push_token": cfg.Ftqq.PushToken}
+	}},
+	"pushplus": {pushplus, func() map[string]string {
+		return map[string]string{"pushplus.push_token": cfg.Pushplus.PushToken}
+	}},
+	"cqhttp": {cqhttp, func() map[string]string {
+		if cqhttpToGroup() {
+			groupID := ""
+			if cfg.Cqhttp.GroupID != 0 {
+				groupID = strconv.Itoa(cfg.Cqhttp.GroupID)
+			}
+			return map[string]string{"cqhttp.cqhttp_url": cfg.Cqhttp.URL, "cqhttp.group_id": groupID}
+		}
+		qq := ""
+		if cfg.Cqhttp.QQ != 0 {
+			qq = strconv.Itoa(cfg.Cqhttp.QQ)
+		}
+		return map[string]string{"cqhttp.cqhttp_url": cfg.Cqhttp.URL, "cqhttp.cqhttp_qq": qq}
+	}},
+	"smtp": {smtpSend, func() map[string]string {
+		return map[string]string{"smtp.mailhost": cfg.Smtp.MailHost, "smtp.port": cfg.Smtp.Port, "smtp.fromaddr": cfg.Smtp.FromAddr, "smtp.toaddr": cfg.Smtp.ToAddr}
+	}},
+	"wecom": {wecom, func() map[string]string {
+		return map[string]string{"wecom.wechat_id": cfg.Wecom.WechatID, "wecom.secret": cfg.Wecom.Secret, "wecom.agentid": cfg.Wecom.AgentID}
+	}},
+	"wecomrobot": {wecomRobot, func() map[string]string {
+		return map[string]string{"wecomrobot.url": cfg.WecomRobot.URL}
+	}},
+	"pushdeer": {pushdeer, func() map[string]string {
+		return map[string]string{"pushdeer.api_url": cfg.Pushdeer.APIURL, "pushdeer.token": cfg.Pushdeer.Token}
+	}},
+	"dingrobot": {dingrobot, func() map[string]string {
+		return map[string]string{"dingrobot.webhook": cfg.Dingrobot.Webhook}
+	}},
+	"feishubot": {feishu, func() map[string]string {
+		return map[string]string{"feishubot.webhook": cfg.Feishu.Webhook}
+	}},
+	"bark": {bark, func() map[string]string {
+		return map[string]string{"bark.api_url": cfg.Bark.APIURL, "bark.token": cfg.Bark.Token}
+	}},
+	"gotify": {gotify, func() map[string]string {
+		return map[string]string{"gotify.api_url": cfg.Gotify.APIURL, "gotify.token": cfg.Gotify.Token}
+	}},
+	"ifttt": {ifttt, func() map[string]string {
+		return map[string]string{"ifttt.event": cfg.Ifttt.Event, "ifttt.key": cfg.Ifttt.Key}
+	}},
+	"webhook": {webhook, func() map[string]string {
+		return map[string]string{"webhook.webhook_url": cfg.Webhook.URL}
+	}},
+	"qmsg": {qmsg, func() map[string]string {
+		return map[string]string{"qmsg.key": cfg.Qmsg.Key}
+	}},
+	"discord": {discord, func() map[string]string {
+		return map[string]string{"discord.webhook": cfg.Discord.Webhook}
+	}},
+	"line": {line, func() map[string]string {
+		return map[string]string{"line.token": cfg.Line.Token}
+	}},
+	"twilio": {twilio, func() map[string]string {
+		return map[string]string{"twilio.account_sid": cfg.Twilio.AccountSID, "twilio.auth_token": cfg.Twilio.AuthToken, "twilio.from": cfg.Twilio.From, "twilio.to": cfg.Twilio.To}
+	}},
+	"sendgrid": {sendgrid, func() map[string]string {
+		return map[string]string{"sendgrid.api_key": cfg.Sendgrid.APIKey, "sendgrid.from": cfg.Sendgrid.From, "sendgrid.to": cfg.Sendgrid.To}
+	}},
+	"github": {github, func() map[string]string {
+		issue := ""
+		if cfg.GitHub.Issue != 0 {
+			issue = strconv.Itoa(cfg.GitHub.Issue)
+		}
+		return map[string]string{"github.token": cfg.GitHub.Token, "github.repo": cfg.GitHub.Repo, "github.issue": issue}
+	}},
+}
+
+// missingFields 返回渠道未填写的必填配置项
+func (c channel) missingFields() []string {
+	var missing []string
+	for field, value := range c.required() {
+		if strings.TrimSpace(value) == "" {
+			missing = append(missing, field)
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
+
 // ================== 主逻辑 ==================
-func Send(title, msg string) {
-	// 使用当前工作目录下的 push.yaml 作为配置文件
-	configPath := "push.yaml"
 
+// configPath 使用当前工作目录下的 push.yaml 作为配置文件
+const configPath = "push.yaml"
+
+// pushServers 解析 push_server 中配置的渠道列表
+func pushServers() []string {
+	return parseServers(cfg.Setting.PushServer)
+}
+
+// failurePushServers 解析 failure_push_server 中配置的失败告警渠道列表
+func failurePushServers() []string {
+	return parseServers(cfg.Setting.FailurePushServer)
+}
+
+// parseServers 解析逗号分隔的渠道列表
+func parseServers(list string) []string {
+	return splitList(strings.ToLower(list))
+}
+
+// splitList 按逗号拆分列表，去除空白和空项
+func splitList(list string) []string {
+	var items []string
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// Validate 校验推送配置，返回渠道名无效或缺少必填配置等问题
+func Validate() []error {
+	if err := loadConfig(configPath); err != nil {
+		return []error{err}
+	}
+
+	var problems []error
+	for _, name := range append(pushServers(), failurePushServers()...) {
+		ch, ok := channels[name]
+		if !ok {
+			problems = append(problems, fmt.Errorf("未知推送方式: %s", name))
+			continue
+		}
+		for _, field := range ch.missingFields() {
+			problems = append(problems, fmt.Errorf("推送方式 %s 缺少必填配置: %s", name, field))
+		}
+		// message_type 只能在 group_id 和 cqhttp_qq 之间选择，不能指定其他类型
+		if name == "cqhttp" && cfg.Cqhttp.MessageType != "" && cfg.Cqhttp.MessageType != "group" && cfg.Cqhttp.MessageType != "private" {
+			problems = append(problems, fmt.Errorf("推送方式 cqhttp 的 message_type 只能是 private 或 group: %s", cfg.Cqhttp.MessageType))
+		}
+	}
+
+	return problems
+}
+
+func Send(title, msg string) {
 	err := loadConfig(configPath)
 	if err != nil {
 		logger.Error("加载配置失败: %v", err)
 		return
 	}
 
-	servers := cfg.Setting.PushServer
-	if servers == "" {
+	servers := pushServers()
+	if len(servers) == 0 {
 		logger.Info("未配置任何推送方式，跳过推送")
 		return
 	}
 
-	for _, s := range strings.Split(strings.ToLower(servers), ",") {
-		switch strings.TrimSpace(s) {
-		case "telegram":
-			telegram(title, msg)
-		case "ftqq":
-			ftqq(title, msg)
-		case "pushplus":
-			pushplus(title, msg)
-		case "cqhttp":
-			cqhttp(title, msg)
-		case "smtp":
-			smtpSend(title, msg)
-		case "wecom":
-			wecom(title, msg)
-		case "wecomrobot":
-			wecomRobot(title, msg)
-		case "pushdeer":
-			pushdeer(title, msg)
-		case "dingrobot":
-			dingrobot(title, msg)
-		case "feishubot":
-			feishu(title, msg)
-		case "bark":
-			bark(title, msg)
-		case "gotify":
-			gotify(title, msg)
-		case "ifttt":
-			ifttt(title, msg)
-		case "webhook":
-			webhook(title, msg)
-		case "qmsg":
-			qmsg(title, msg)
-		case "discord":
-			discord(title, msg)
-		default:
+	if !markSent(title, msg) {
+		logger.Info("相同内容的通知在去重窗口内已推送，跳过: %s", title)
+		return
+	}
+
+	sendTo(servers, title, msg)
+}
+
+// SendFailure 向 failure_push_server 配置的渠道发送更新失败告警，未配置时不发送
+func SendFailure(title, msg string) {
+	err := loadConfig(configPath)
+	if err != nil {
+		logger.Error("加载配置失败: %v", err)
+		return
+	}
+
+	servers := failurePushServers()
+	if len(servers) == 0 {
+		return
+	}
+
+	if !markSent(title, msg) {
+		logger.Info("相同内容的通知在去重窗口内已推送，跳过: %s", title)
+		return
+	}
+
+	sendTo(servers, title, msg)
+}
+
+// sendTo 向指定渠道推送消息
+func sendTo(servers []string, title, msg string) {
+	for _, s := range servers {
+		ch, ok := channels[s]
+		if !ok {
 			logger.Warn("未知推送方式: %s", s)
+			continue
+		}
+
+		// 超过渠道长度上限时分段发送
+		segments := splitMessage(title, msg, messageLimits[s])
+		for i, segment := range segments {
+			segmentTitle := title
+			if len(segments) > 1 {
+				segmentTitle = fmt.Sprintf("%s (%d/%d)", title, i+1, len(segments))
+			}
+			ch.send(segmentTitle, segment)
 		}
 	}
 }
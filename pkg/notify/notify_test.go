@@ -0,0 +1,36 @@
+package notify
+
+import "testing"
+
+func TestUpdateHTTPClientReuse(t *testing.T) {
+	t.Cleanup(func() { httpClientSettings = nil })
+
+	if err := updateHTTPClient("", "", 10); err != nil {
+		t.Fatalf("创建 HTTP 客户端失败: %v", err)
+	}
+	first := httpClient
+
+	// 配置未变化时复用同一客户端和连接池
+	if err := updateHTTPClient("", "", 10); err != nil {
+		t.Fatalf("加载配置失败: %v", err)
+	}
+	if httpClient != first {
+		t.Error("配置未变化时不应重建 HTTP 客户端")
+	}
+
+	if err := updateHTTPClient("", "http://127.0.0.1:3128", 10); err != nil {
+		t.Fatalf("加载配置失败: %v", err)
+	}
+	if httpClient == first {
+		t.Error("proxy 变化时应重建 HTTP 客户端")
+	}
+
+	// 配置有误时保留当前客户端
+	current := httpClient
+	if err := updateHTTPClient("", "://bad", 10); err == nil {
+		t.Fatal("无效的代理地址应返回错误")
+	}
+	if httpClient != current {
+		t.Error("创建失败时不应替换当前 HTTP 客户端")
+	}
+}
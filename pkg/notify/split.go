@@ -0,0 +1,80 @@
+package notify
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// messageLimits 各渠道单条消息的字符数上限（含标题），未列出的渠道不分段。
+// 按字节计算上限的渠道按每个中文字符 3 字节折算
+var messageLimits = map[string]int{
+	"telegram":   4096,
+	"wecom":      680,
+	"wecomrobot": 680,
+	"dingrobot":  6000,
+	"feishubot":  10000,
+	"discord":    4096,
+	"line":       1000,
+	"github":     65000,
+}
+
+// maxSegments 单次推送最多拆分的段数，超出部分不再发送
+const maxSegments = 5
+
+// segmentSuffixReserve 为标题后的分段序号（如 " (1/5)"）预留的字符数
+const segmentSuffixReserve = 6
+
+// omittedNoteReserve 为最后一段末尾的省略提示预留的字符数
+const omittedNoteReserve = 20
+
+// splitMessage 按行将消息拆分为多段，使每段加上标题后不超过 limit 个字符。
+// 超过 maxSegments 段时截断并在末尾提示未显示的行数
+func splitMessage(title, msg string, limit int) []string {
+	titleLen := utf8.RuneCountInString(title)
+	if limit <= 0 || titleLen+1+utf8.RuneCountInString(msg) <= limit {
+		return []string{msg}
+	}
+
+	budget := limit - titleLen - 1 - segmentSuffixReserve
+	if budget <= omittedNoteReserve {
+		// 标题过长时无法分段，直接截断
+		return []string{truncate(msg, max(limit-titleLen-1, 1))}
+	}
+
+	lines := strings.Split(strings.TrimRight(msg, "\n"), "\n")
+	var segments []string
+	var current []string
+	currentLen := 0
+
+	for i, line := range lines {
+		line = truncate(line, budget)
+		lineLen := utf8.RuneCountInString(line)
+
+		segmentBudget := budget
+		if len(segments) == maxSegments-1 {
+			segmentBudget = budget - omittedNoteReserve
+		}
+
+		if len(current) > 0 && currentLen+1+lineLen > segmentBudget {
+			if len(segments) == maxSegments-1 {
+				note := fmt.Sprintf("…还有 %d 行未显示", len(lines)-i)
+				segments = append(segments, strings.Join(current, "\n")+"\n"+note)
+				return segments
+			}
+			segments = append(segments, strings.Join(current, "\n"))
+			current, currentLen = nil, 0
+		}
+
+		if len(current) > 0 {
+			currentLen++
+		}
+		current = append(current, line)
+		currentLen += lineLen
+	}
+
+	if len(current) > 0 {
+		segments = append(segments, strings.Join(current, "\n"))
+	}
+	return segments
+}
@@ -0,0 +1,122 @@
+package state
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// ContainerState 单个容器的检查与更新状态
+type ContainerState struct {
+	LastChecked   time.Time `json:"last_checked"`
+	LastUpdated   time.Time `json:"last_updated"`
+	LastImageHash string    `json:"last_image_hash"`
+}
+
+// ImageState 单个镜像上次检查时 registry 上的摘要
+type ImageState struct {
+	ManifestDigest string    `json:"manifest_digest"`           // registry 返回的 manifest 摘要，多架构镜像为清单摘要
+	PlatformDigest string    `json:"platform_digest,omitempty"` // 多架构清单中 Docker 主机平台对应的 manifest 摘要
+	RemoteHash     string    `json:"remote_hash"`               // 拉取后得到的镜像摘要
+	CheckedAt      time.Time `json:"checked_at"`
+}
+
+// Store 容器状态文件，记录每个容器上次检查和更新的时间，以及镜像摘要缓存
+type Store struct {
+	path       string
+	Containers map[string]*ContainerState `json:"containers"`
+	Images     map[string]*ImageState     `json:"images,omitempty"`
+}
+
+// Key 生成容器在状态文件中的键，非本地主机的容器带上主机前缀
+func Key(host, name string) string {
+	if host == "" {
+		return name
+	}
+	return host + "/" + name
+}
+
+// Load 加载状态文件，文件不存在时返回空状态
+func Load(path string) (*Store, error) {
+	store := &Store{
+		path:       path,
+		Containers: make(map[string]*ContainerState),
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取状态文件失败: %w", err)
+	}
+
+	if err := json.Unmarshal(data, store); err != nil {
+		return nil, fmt.Errorf("解析状态文件失败: %w", err)
+	}
+	if store.Containers == nil {
+		store.Containers = make(map[string]*ContainerState)
+	}
+
+	return store, nil
+}
+
+// Get 获取容器状态
+func (s *Store) Get(key string) (*ContainerState, bool) {
+	st, ok := s.Containers[key]
+	return st, ok
+}
+
+// RecordCheck 记录容器的检查时间和当前镜像摘要，摘要为空时保留原值
+func (s *Store) RecordCheck(key, imageHash string, at time.Time) {
+	st := s.entry(key)
+	st.LastChecked = at
+	if imageHash != "" {
+		st.LastImageHash = imageHash
+	}
+}
+
+// RecordUpdate 记录容器的更新时间
+func (s *Store) RecordUpdate(key string, at time.Time) {
+	s.entry(key).LastUpdated = at
+}
+
+// entry 获取容器状态，不存在时创建
+func (s *Store) entry(key string) *ContainerState {
+	st, ok := s.Containers[key]
+	if !ok {
+		st = &ContainerState{}
+		s.Containers[key] = st
+	}
+	return st
+}
+
+// Save 将状态写入文件，先写临时文件再重命名，避免写入中断导致文件损坏
+func (s *Store) Save() error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("序列化状态失败: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("创建临时状态文件失败: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("写入状态文件失败: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("写入状态文件失败: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("保存状态文件失败: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+package utils
+
+import "os"
+
+const (
+	colorRed    = "\033[31m"
+	colorGreen  = "\033[32m"
+	colorYellow = "\033[33m"
+	colorReset  = "\033[0m"
+)
+
+// colorEnabled 是否输出 ANSI 颜色
+var colorEnabled = true
+
+// SetColor 设置是否输出 ANSI 颜色
+func SetColor(enabled bool) {
+	colorEnabled = enabled
+}
+
+// IsTerminal 判断文件是否为终端设备
+func IsTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// colorize 为文本添加颜色，禁用颜色时原样返回
+func colorize(s, color string) string {
+	if !colorEnabled {
+		return s
+	}
+	return color + s + colorReset
+}
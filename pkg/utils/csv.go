@@ -0,0 +1,58 @@
+package utils
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+
+	"watchducker/internal/types"
+	"watchducker/pkg/i18n"
+)
+
+// WriteCSV 将本次运行所有主机的容器检查结果导出为 CSV 文件，覆盖已有文件。
+// 文件以 UTF-8 BOM 开头，Excel 打开时中文不会乱码
+func WriteCSV(path string, results []*types.BatchCheckResult) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("创建 CSV 文件失败: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.WriteString("\ufeff"); err != nil {
+		return fmt.Errorf("写入 CSV 文件失败: %w", err)
+	}
+
+	w := csv.NewWriter(file)
+	w.Write([]string{i18n.T("容器"), i18n.T("镜像"), i18n.T("状态"), i18n.T("本地 hash"), i18n.T("远程 hash"), i18n.T("检查时间"), i18n.T("主机")})
+
+	for _, result := range results {
+		if result.Error != "" {
+			w.Write([]string{"", "", i18n.T("❌ 失败") + ": " + result.Error, "", "", "", result.Host})
+		}
+
+		images := make(map[string]*types.ImageCheckResult, len(result.Images))
+		for _, info := range result.Images {
+			images[info.Name] = info
+		}
+
+		for _, container := range result.Containers {
+			var status, localHash, remoteHash, checkedAt string
+			if info, ok := images[container.Image]; ok {
+				status, _ = imageStatus(info)
+				localHash = info.LocalHash
+				remoteHash = info.RemoteHash
+				checkedAt = info.CheckedAt.Format("2006-01-02 15:04:05")
+			}
+			w.Write([]string{container.Name, container.Image, status, localHash, remoteHash, checkedAt, result.Host})
+		}
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return fmt.Errorf("写入 CSV 文件失败: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("写入 CSV 文件失败: %w", err)
+	}
+	return nil
+}
@@ -2,72 +2,260 @@ package utils
 
 import (
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 
 	"watchducker/internal/types"
+	"watchducker/pkg/i18n"
 	"watchducker/pkg/logger"
 )
 
-// PrintContainerList 打印容器列表
-func PrintContainerList(containers []types.ContainerInfo) {
-	fmt.Println("\n=== 容器列表 ===")
+// quiet 安静模式下不逐行输出最新的镜像和容器
+var quiet bool
+
+// SetQuiet 设置是否启用安静模式
+func SetQuiet(enabled bool) {
+	quiet = enabled
+}
+
+// PrintHost 打印当前结果所属的 Docker 主机，本地主机不打印
+func PrintHost(host string) {
+	if host == "" {
+		return
+	}
+	fmt.Printf(i18n.T("\n=== Docker 主机: %s ===\n"), host)
+}
+
+// PrintContainerList 打印容器列表，安静模式下只打印有更新或检查失败的容器
+func PrintContainerList(result *types.BatchCheckResult) {
+	containers := result.Containers
+	if quiet {
+		containers = changedContainers(result)
+	}
+
+	fmt.Println("\n" + i18n.T("=== 容器列表 ==="))
 	if len(containers) == 0 {
-		fmt.Println("未找到匹配的容器")
+		if quiet {
+			fmt.Println(i18n.T("没有需要关注的容器"))
+		} else {
+			fmt.Println(i18n.T("未找到匹配的容器"))
+		}
 		return
 	}
 
-	fmt.Printf("%-12s %-20s %-20s %s\n", "ID", "名称", "镜像", "状态")
-	fmt.Println("----------------------------------------------------------------")
+	fmt.Printf("%s %s %s %s\n", PadRight("ID", 12), PadRight(i18n.T("名称"), 24), PadRight(i18n.T("镜像"), 36), i18n.T("状态"))
+	fmt.Println(strings.Repeat("-", 84))
 
 	for _, container := range containers {
-		fmt.Printf("%-12s %-20s %-20s %s\n",
-			container.ID,
-			container.Name,
-			container.Image,
+		fmt.Printf("%s %s %s %s\n",
+			PadRight(container.ID, 12),
+			PadRight(container.Name, 24),
+			PadRight(container.Image, 36),
 			container.State)
 	}
 }
 
+// PrintImageList 打印镜像检查结果，用于不查找容器的 --image 模式，安静模式下只打印有更新或检查失败的镜像
+func PrintImageList(result *types.BatchCheckResult) {
+	fmt.Println("\n" + i18n.T("=== 镜像列表 ==="))
+	fmt.Printf("%s %s\n", PadRight(i18n.T("镜像"), 48), i18n.T("状态"))
+	fmt.Println(strings.Repeat("-", 64))
+
+	for _, info := range result.Images {
+		if quiet && info.Error == "" && !info.NeedsUpdate() {
+			continue
+		}
+		status, color := imageStatus(info)
+		if color != "" {
+			status = colorize(status, color)
+		}
+		fmt.Printf("%s %s\n", PadRight(info.Name, 48), status)
+	}
+}
+
+// changedContainers 筛选镜像有更新或检查失败的容器
+func changedContainers(result *types.BatchCheckResult) []types.ContainerInfo {
+	changed := make(map[string]struct{})
+	switching := make(map[string]struct{})
+	for _, info := range result.Images {
+		if info.Error != "" || info.IsUpdated {
+			changed[info.Name] = struct{}{}
+		}
+		for _, id := range info.SwitchContainers {
+			switching[id] = struct{}{}
+		}
+	}
+
+	var containers []types.ContainerInfo
+	for _, container := range result.Containers {
+		_, imageChanged := changed[container.Image]
+		_, switched := switching[container.ID]
+		if imageChanged || switched {
+			containers = append(containers, container)
+		}
+	}
+	return containers
+}
+
+// SortByStatus 按更新状态对容器和镜像排序：有更新、失败、无法确认、跳过、最新，同一状态内保持原顺序
+func SortByStatus(result *types.BatchCheckResult) {
+	ranks := make(map[string]int, len(result.Images))
+	for _, info := range result.Images {
+		ranks[info.Name] = statusRank(info)
+	}
+
+	sort.SliceStable(result.Images, func(i, j int) bool {
+		return statusRank(result.Images[i]) < statusRank(result.Images[j])
+	})
+	sort.SliceStable(result.Containers, func(i, j int) bool {
+		return containerRank(ranks, result.Containers[i]) < containerRank(ranks, result.Containers[j])
+	})
+}
+
+// statusRank 返回镜像检查结果的排序优先级，越小越靠前
+func statusRank(info *types.ImageCheckResult) int {
+	switch {
+	case info.NeedsUpdate():
+		return 0
+	case info.Reason == types.ReasonRemoteError:
+		return 2
+	case info.Error != "":
+		return 1
+	case info.Reason != "":
+		return 3
+	}
+	return 4
+}
+
+// containerRank 返回容器的排序优先级，没有对应镜像检查结果的容器排在最后
+func containerRank(ranks map[string]int, container types.ContainerInfo) int {
+	if rank, ok := ranks[container.Image]; ok {
+		return rank
+	}
+	return 5
+}
+
 // PrintBatchSummary 打印批量检查的统计信息
 func PrintBatchSummary(result *types.BatchCheckResult) {
-	fmt.Println("\n=== 统计信息 ===")
-	fmt.Printf("匹配的容器数: %d\n", result.Summary.TotalContainers)
-	fmt.Printf("检查的镜像数: %d\n", result.Summary.TotalImages)
-	fmt.Printf("有更新的镜像: %d\n", result.Summary.Updated)
-	fmt.Printf("最新的镜像: %d\n", result.Summary.UpToDate)
-	fmt.Printf("检查失败的镜像: %d\n", result.Summary.Failed)
-	fmt.Printf("检查耗时: %v\n", result.Summary.Duration.Round(time.Millisecond))
+	fmt.Println("\n" + i18n.T("=== 统计信息 ==="))
+	fmt.Printf(i18n.T("匹配的容器数: %d\n"), result.Summary.TotalContainers)
+	fmt.Printf(i18n.T("检查的镜像数: %d\n"), result.Summary.TotalImages)
+	fmt.Printf(i18n.T("有更新的镜像: %d\n"), result.Summary.Updated)
+	fmt.Printf(i18n.T("最新的镜像: %d\n"), result.Summary.UpToDate)
+	fmt.Printf(i18n.T("跳过的镜像: %d\n"), result.Summary.Skipped)
+	fmt.Printf(i18n.T("无法确认的镜像: %d\n"), result.Summary.Unknown)
+	fmt.Printf(i18n.T("检查失败的镜像: %d\n"), result.Summary.Failed)
+	fmt.Printf(i18n.T("检查耗时: %v\n"), result.Summary.Duration.Round(time.Millisecond))
+}
+
+// imageStatus 返回镜像检查结果的状态描述及终端显示颜色，颜色为空表示不着色
+func imageStatus(info *types.ImageCheckResult) (string, string) {
+	if info.Reason == types.ReasonRemoteError {
+		return i18n.T("❔ 无法确认"), colorYellow
+	} else if info.Error != "" {
+		return i18n.T("❌ 失败"), colorRed
+	} else if info.IsUpdated && info.UpdateRef != "" {
+		return fmt.Sprintf(i18n.T("🔄 有新版本 %s"), info.UpdateRef), colorYellow
+	} else if info.IsUpdated {
+		return i18n.T("🔄 有更新"), colorYellow
+	} else if len(info.SwitchContainers) > 0 {
+		return fmt.Sprintf(i18n.T("🔀 %d 个容器待切换"), len(info.SwitchContainers)), colorYellow
+	} else if info.Reason == types.ReasonLocalPulled {
+		return i18n.T("📥 已拉取"), ""
+	} else if info.Reason == types.ReasonPinned {
+		return i18n.T("📌 已固定"), ""
+	} else if info.Reason == types.ReasonLocalOnly {
+		return i18n.T("🏠 本地镜像"), ""
+	} else if info.Reason == types.ReasonRegistrySkipped {
+		return i18n.T("⏭️ 已忽略"), ""
+	} else if info.Reason == types.ReasonTooNew {
+		return i18n.T("⏳ 等待稳定"), colorYellow
+	}
+	return i18n.T("✅ 最新"), colorGreen
 }
 
-// CreateCheckCallback 创建镜像检查回调函数
+// CreateCheckCallback 创建镜像检查回调函数，输出每个镜像的检查结果和整体进度
 func CreateCheckCallback() types.CheckCallback {
-	return func(info *types.ImageCheckResult) {
-		status := "✅ 最新"
-		if info.Error != "" {
-			status = "❌ 失败"
-		} else if info.IsUpdated {
-			status = "🔄 有更新"
+	return func(info *types.ImageCheckResult, done, total int) {
+		if quiet && info.Error == "" && !info.NeedsUpdate() {
+			logger.Debug("检查进度: %d/%d", done, total)
+			return
 		}
-		logger.Info("镜像 %-20s %s", info.Name, status)
+		// 颜色只由 logger 在标准输出中添加，日志文件和 JSON 日志中保持原文
+		status, color := imageStatus(info)
+		logger.Info("[%d/%d] 镜像 %-20s %s", done, total, info.Name, logger.Colored{Text: status, Color: color})
 	}
 }
 
 func GetUpdateSummary(result *types.BatchCheckResult) string {
 	var summary string
-	summary += "\n=== 更新信息 ===\n"
+	if result.Host != "" {
+		summary += fmt.Sprintf(i18n.T("\nDocker 主机: %s"), result.Host)
+	}
+	summary += i18n.T("\n=== 更新信息 ===\n")
 	for _, item := range result.Images {
-		if item.IsUpdated && item.Error == "" {
-			summary += fmt.Sprintf("镜像 %-20s 更新成功✅\n", item.Name)
+		if item.Reason == types.ReasonRemoteError {
+			summary += fmt.Sprintf(i18n.T("镜像 %-20s 无法确认更新❔: %s\n"), item.Name, item.Error)
 		} else if item.Error != "" {
-			summary += fmt.Sprintf("镜像 %-20s 更新失败❌: %s\n", item.Name, item.Error)
+			summary += fmt.Sprintf(i18n.T("镜像 %-20s 更新失败❌: %s\n"), item.Name, item.Error)
 		}
 	}
+	for _, update := range result.Updates {
+		summary += updateLine(update) + "\n"
+	}
 	return summary
 }
 
+// GetFailureSummary 生成更新失败的容器列表，没有容器更新失败时返回空字符串
+func GetFailureSummary(result *types.BatchCheckResult) string {
+	var summary string
+	for _, update := range result.Updates {
+		if !update.Success && !update.Skipped {
+			summary += updateLine(update) + "\n"
+		}
+	}
+	if summary == "" {
+		return ""
+	}
+
+	if result.Host != "" {
+		summary = fmt.Sprintf(i18n.T("\nDocker 主机: %s"), result.Host) + "\n" + summary
+	}
+	return summary
+}
+
+// updateLine 生成单个容器更新结果的描述
+func updateLine(update types.ContainerUpdateResult) string {
+	if update.Success {
+		return fmt.Sprintf(i18n.T("容器 %-20s 更新成功✅ (%s)"), update.Name, update.Image)
+	} else if update.Skipped {
+		return fmt.Sprintf(i18n.T("容器 %-20s 跳过更新⏭️: %s"), update.Name, update.Error)
+	}
+	return fmt.Sprintf(i18n.T("容器 %-20s 更新失败❌: %s"), update.Name, update.Error)
+}
+
+// PrintUpdateResults 打印每个容器的更新结果，未执行更新时不打印
+func PrintUpdateResults(result *types.BatchCheckResult) {
+	if len(result.Updates) == 0 {
+		return
+	}
+
+	fmt.Println("\n" + i18n.T("=== 更新结果 ==="))
+	for _, update := range result.Updates {
+		line := updateLine(update)
+		if update.Success {
+			line = colorize(line, colorGreen)
+		} else if !update.Skipped {
+			line = colorize(line, colorRed)
+		}
+		fmt.Println(line)
+	}
+}
+
 // PrintWelcome 打印欢迎信息
 func PrintWelcome() {
 	fmt.Println("========================================")
-	fmt.Println("      WatchDucker - Docker 镜像更新检查器")
+	fmt.Println(i18n.T("      WatchDucker - Docker 镜像更新检查器"))
 	fmt.Println("========================================")
 }
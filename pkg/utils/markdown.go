@@ -0,0 +1,72 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"watchducker/internal/types"
+	"watchducker/pkg/i18n"
+)
+
+// WriteMarkdownReport 将本次运行所有主机的检查结果写成 Markdown 表格报告，覆盖已有文件
+func WriteMarkdownReport(path string, results []*types.BatchCheckResult) error {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# %s\n\n", i18n.T("WatchDucker 检查报告"))
+	fmt.Fprintf(&b, i18n.T("生成时间: %s\n"), time.Now().Format("2006-01-02 15:04:05"))
+
+	for _, result := range results {
+		b.WriteString("\n")
+		if result.Host != "" {
+			fmt.Fprintf(&b, i18n.T("## Docker 主机: %s\n\n"), result.Host)
+		}
+		if result.Error != "" {
+			fmt.Fprintf(&b, i18n.T("❌ 主机处理失败: %s\n\n"), result.Error)
+		}
+
+		images := make(map[string]*types.ImageCheckResult, len(result.Images))
+		for _, info := range result.Images {
+			images[info.Name] = info
+		}
+
+		fmt.Fprintf(&b, "| %s | %s | %s | %s | %s | %s |\n",
+			i18n.T("容器"), i18n.T("镜像"), i18n.T("状态"), i18n.T("旧 hash"), i18n.T("新 hash"), i18n.T("检查时间"))
+		b.WriteString("| --- | --- | --- | --- | --- | --- |\n")
+
+		for _, container := range result.Containers {
+			status, localHash, remoteHash, checkedAt := "-", "-", "-", "-"
+			if info, ok := images[container.Image]; ok {
+				status, _ = imageStatus(info)
+				localHash = markdownHash(info.LocalHash)
+				remoteHash = markdownHash(info.RemoteHash)
+				checkedAt = info.CheckedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Fprintf(&b, "| %s | %s | %s | %s | %s | %s |\n",
+				markdownEscape(container.Name), markdownEscape(container.Image), status, localHash, remoteHash, checkedAt)
+		}
+
+		fmt.Fprintf(&b, i18n.T("\n更新 %d，最新 %d，跳过 %d，未知 %d，失败 %d，耗时 %v\n"),
+			result.Summary.Updated, result.Summary.UpToDate, result.Summary.Skipped, result.Summary.Unknown, result.Summary.Failed,
+			result.Summary.Duration.Round(time.Millisecond))
+	}
+
+	if err := os.WriteFile(path, []byte(b.String()), 0644); err != nil {
+		return fmt.Errorf("写入 Markdown 报告失败: %w", err)
+	}
+	return nil
+}
+
+// markdownHash 截短摘要便于阅读，空值显示为 -
+func markdownHash(hash string) string {
+	if hash == "" {
+		return "-"
+	}
+	return "`" + ShortID(strings.TrimPrefix(hash, "sha256:")) + "`"
+}
+
+// markdownEscape 转义会破坏表格结构的竖线
+func markdownEscape(s string) string {
+	return strings.ReplaceAll(s, "|", "\\|")
+}
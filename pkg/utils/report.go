@@ -0,0 +1,39 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"watchducker/internal/types"
+)
+
+// reportRecord 报告文件中的单条记录
+type reportRecord struct {
+	Timestamp time.Time               `json:"timestamp"`
+	Result    *types.BatchCheckResult `json:"result"`
+}
+
+// AppendReport 将检查结果以 JSON Lines 格式追加写入报告文件
+func AppendReport(path string, result *types.BatchCheckResult) error {
+	line, err := json.Marshal(reportRecord{
+		Timestamp: time.Now(),
+		Result:    result,
+	})
+	if err != nil {
+		return fmt.Errorf("序列化检查结果失败: %w", err)
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("打开报告文件失败: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("写入报告文件失败: %w", err)
+	}
+
+	return nil
+}
@@ -96,3 +96,8 @@ func StructMapSubtract(m1, m2 map[string]struct{}) map[string]struct{} {
 
 	return m
 }
+
+// ShortID 返回容器或镜像 ID 的前 12 位，ID 不足 12 位时原样返回
+func ShortID(id string) string {
+	return id[:min(len(id), 12)]
+}
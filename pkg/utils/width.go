@@ -0,0 +1,57 @@
+package utils
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// runeWidth 返回字符在终端中的显示宽度，东亚宽字符和全角字符占两列
+func runeWidth(r rune) int {
+	switch {
+	case r == 0 || unicode.Is(unicode.Mn, r):
+		return 0
+	case unicode.In(r, unicode.Han, unicode.Hangul, unicode.Hiragana, unicode.Katakana),
+		r >= 0x3000 && r <= 0x303F,   // CJK 标点
+		r >= 0xFF01 && r <= 0xFF60,   // 全角 ASCII
+		r >= 0xFFE0 && r <= 0xFFE6,   // 全角符号
+		r >= 0x1F300 && r <= 0x1FAFF: // emoji
+		return 2
+	}
+	return 1
+}
+
+// DisplayWidth 返回字符串在终端中的显示宽度
+func DisplayWidth(s string) int {
+	width := 0
+	for _, r := range s {
+		width += runeWidth(r)
+	}
+	return width
+}
+
+// PadRight 按显示宽度将字符串补齐到 width 列，超长时截断并以省略号结尾
+func PadRight(s string, width int) string {
+	w := DisplayWidth(s)
+	if w <= width {
+		return s + strings.Repeat(" ", width-w)
+	}
+
+	// 预留一列给省略号
+	var b strings.Builder
+	used := 0
+	for len(s) > 0 {
+		r, size := utf8.DecodeRuneInString(s)
+		rw := runeWidth(r)
+		if used+rw > width-1 {
+			break
+		}
+		b.WriteRune(r)
+		used += rw
+		s = s[size:]
+	}
+	b.WriteString("…")
+	used++
+
+	return b.String() + strings.Repeat(" ", width-used)
+}